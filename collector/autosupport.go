@@ -0,0 +1,200 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+var (
+	autosupportDeliveryMethods  = []string{"https", "http", "smtp"}
+	autosupportDispatchStatuses = []string{"success", "failed"}
+)
+
+type Autosupport struct {
+	AsupEnabled        bool                `json:"asupEnabled"`
+	OnDemandEnabled    bool                `json:"onDemandEnabled"`
+	RemoteDiagsEnabled bool                `json:"remoteDiagsEnabled"`
+	Delivery           AutosupportDelivery `json:"delivery"`
+}
+
+type AutosupportDelivery struct {
+	Method string `json:"method"`
+}
+
+type AutosupportDispatch struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+	Status    string `json:"status"`
+	Time      time.Time
+}
+
+type AutosupportCollector struct {
+	Enabled                         *prometheus.Desc
+	OnDemandEnabled                 *prometheus.Desc
+	RemoteDiagsEnabled              *prometheus.Desc
+	DeliveryMethod                  *prometheus.Desc
+	LastDispatchTimestamp           *prometheus.Desc
+	LastDispatchStatus              *prometheus.Desc
+	LastSuccessfulDispatchTimestamp *prometheus.Desc
+	ctx                             context.Context
+	target                          config.Target
+	logger                          log.Logger
+}
+
+func init() {
+	registerCollector("autosupport", false, NewAutosupportExporter)
+}
+
+func NewAutosupportExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	return &AutosupportCollector{
+		Enabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "autosupport", "enabled"),
+			"AutoSupport enabled, 1=enabled 0=disabled", nil, nil),
+		OnDemandEnabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "autosupport", "on_demand_enabled"),
+			"AutoSupport OnDemand enabled, 1=enabled 0=disabled", nil, nil),
+		RemoteDiagsEnabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "autosupport", "remote_diagnostics_enabled"),
+			"AutoSupport remote diagnostics enabled, 1=enabled 0=disabled", nil, nil),
+		DeliveryMethod: prometheus.NewDesc(prometheus.BuildFQName(namespace, "autosupport", "delivery_method"),
+			"AutoSupport delivery method", []string{"method"}, nil),
+		LastDispatchTimestamp: prometheus.NewDesc(prometheus.BuildFQName(namespace, "autosupport", "last_dispatch_timestamp_seconds"),
+			"Timestamp of the last AutoSupport dispatch", nil, nil),
+		LastDispatchStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, "autosupport", "last_dispatch_status"),
+			"Result of the last AutoSupport dispatch", []string{"status"}, nil),
+		LastSuccessfulDispatchTimestamp: prometheus.NewDesc(prometheus.BuildFQName(namespace, "autosupport", "last_successful_dispatch_timestamp_seconds"),
+			"Timestamp of the last successful AutoSupport dispatch", nil, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *AutosupportCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Enabled
+	ch <- c.OnDemandEnabled
+	ch <- c.RemoteDiagsEnabled
+	ch <- c.DeliveryMethod
+	ch <- c.LastDispatchTimestamp
+	ch <- c.LastDispatchStatus
+	ch <- c.LastSuccessfulDispatchTimestamp
+}
+
+func (c *AutosupportCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting autosupport metrics")
+	collectTime := time.Now()
+	var errorReason string
+	asup, dispatches, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	if err == nil {
+		ch <- prometheus.MustNewConstMetric(c.Enabled, prometheus.GaugeValue, boolToFloat64(asup.AsupEnabled))
+		ch <- prometheus.MustNewConstMetric(c.OnDemandEnabled, prometheus.GaugeValue, boolToFloat64(asup.OnDemandEnabled))
+		ch <- prometheus.MustNewConstMetric(c.RemoteDiagsEnabled, prometheus.GaugeValue, boolToFloat64(asup.RemoteDiagsEnabled))
+		for _, method := range autosupportDeliveryMethods {
+			var value float64
+			if method == asup.Delivery.Method {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.DeliveryMethod, prometheus.GaugeValue, value, method)
+		}
+		var unknown float64
+		if !sliceContains(autosupportDeliveryMethods, asup.Delivery.Method) {
+			unknown = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.DeliveryMethod, prometheus.GaugeValue, unknown, "unknown")
+
+		var last, lastSuccessful *AutosupportDispatch
+		for i := range dispatches {
+			d := &dispatches[i]
+			if last == nil || d.Time.After(last.Time) {
+				last = d
+			}
+			if d.Status == "success" && (lastSuccessful == nil || d.Time.After(lastSuccessful.Time)) {
+				lastSuccessful = d
+			}
+		}
+		if last != nil {
+			ch <- prometheus.MustNewConstMetric(c.LastDispatchTimestamp, prometheus.GaugeValue, float64(last.Time.Unix()))
+			for _, status := range autosupportDispatchStatuses {
+				var value float64
+				if status == last.Status {
+					value = 1
+				}
+				ch <- prometheus.MustNewConstMetric(c.LastDispatchStatus, prometheus.GaugeValue, value, status)
+			}
+			var unknown float64
+			if !sliceContains(autosupportDispatchStatuses, last.Status) {
+				unknown = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.LastDispatchStatus, prometheus.GaugeValue, unknown, "unknown")
+		}
+		if lastSuccessful != nil {
+			ch <- prometheus.MustNewConstMetric(c.LastSuccessfulDispatchTimestamp, prometheus.GaugeValue, float64(lastSuccessful.Time.Unix()))
+		}
+	}
+
+	collectErrorMetrics(ch, "autosupport", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "autosupport")
+}
+
+func (c *AutosupportCollector) collect() (Autosupport, []AutosupportDispatch, error) {
+	var asup Autosupport
+	var dispatches []AutosupportDispatch
+	var asupBody, dispatchesBody []byte
+	var asupErr, dispatchesErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		asupBody, asupErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/device-asup", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		dispatchesBody, dispatchesErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/device-asup/history", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if asupErr != nil {
+		return asup, nil, asupErr
+	}
+	if dispatchesErr != nil {
+		return asup, nil, dispatchesErr
+	}
+	err := json.Unmarshal(asupBody, &asup)
+	if err != nil {
+		return asup, nil, err
+	}
+	err = json.Unmarshal(dispatchesBody, &dispatches)
+	if err != nil {
+		return asup, nil, err
+	}
+	for i := range dispatches {
+		d := &dispatches[i]
+		d.Time, err = parseTimestamp(d.Timestamp)
+		if err != nil {
+			return asup, nil, err
+		}
+	}
+	return asup, dispatches, nil
+}
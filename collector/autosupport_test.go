@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestAutosupportCollector(t *testing.T) {
+	asupData, err := os.ReadFile("testdata/device-asup.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	historyData, err := os.ReadFile("testdata/device-asup-history.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_autosupport_delivery_method AutoSupport delivery method
+	# TYPE eseries_autosupport_delivery_method gauge
+	eseries_autosupport_delivery_method{method="http"} 0
+	eseries_autosupport_delivery_method{method="https"} 1
+	eseries_autosupport_delivery_method{method="smtp"} 0
+	eseries_autosupport_delivery_method{method="unknown"} 0
+	# HELP eseries_autosupport_enabled AutoSupport enabled, 1=enabled 0=disabled
+	# TYPE eseries_autosupport_enabled gauge
+	eseries_autosupport_enabled 1
+	# HELP eseries_autosupport_last_dispatch_status Result of the last AutoSupport dispatch
+	# TYPE eseries_autosupport_last_dispatch_status gauge
+	eseries_autosupport_last_dispatch_status{status="failed"} 1
+	eseries_autosupport_last_dispatch_status{status="success"} 0
+	eseries_autosupport_last_dispatch_status{status="unknown"} 0
+	# HELP eseries_autosupport_last_dispatch_timestamp_seconds Timestamp of the last AutoSupport dispatch
+	# TYPE eseries_autosupport_last_dispatch_timestamp_seconds gauge
+	eseries_autosupport_last_dispatch_timestamp_seconds 1.585710687e+09
+	# HELP eseries_autosupport_last_successful_dispatch_timestamp_seconds Timestamp of the last successful AutoSupport dispatch
+	# TYPE eseries_autosupport_last_successful_dispatch_timestamp_seconds gauge
+	eseries_autosupport_last_successful_dispatch_timestamp_seconds 1.585624202e+09
+	# HELP eseries_autosupport_on_demand_enabled AutoSupport OnDemand enabled, 1=enabled 0=disabled
+	# TYPE eseries_autosupport_on_demand_enabled gauge
+	eseries_autosupport_on_demand_enabled 1
+	# HELP eseries_autosupport_remote_diagnostics_enabled AutoSupport remote diagnostics enabled, 1=enabled 0=disabled
+	# TYPE eseries_autosupport_remote_diagnostics_enabled gauge
+	eseries_autosupport_remote_diagnostics_enabled 0
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="autosupport",reason="auth"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="connection"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="decode"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="other"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "device-asup/history") {
+			_, _ = rw.Write(historyData)
+		} else if strings.HasSuffix(req.URL.Path, "device-asup") {
+			_, _ = rw.Write(asupData)
+		} else {
+			rw.WriteHeader(http.StatusNotFound)
+			_, _ = rw.Write([]byte(""))
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewAutosupportExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 20 {
+		t.Errorf("Unexpected collection count %d, expected 20", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_autosupport_enabled", "eseries_autosupport_on_demand_enabled",
+		"eseries_autosupport_remote_diagnostics_enabled", "eseries_autosupport_delivery_method",
+		"eseries_autosupport_last_dispatch_timestamp_seconds", "eseries_autosupport_last_dispatch_status",
+		"eseries_autosupport_last_successful_dispatch_timestamp_seconds", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestAutosupportCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="autosupport",reason="auth"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="connection"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="decode"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="autosupport",reason="other"} 0
+	eseries_exporter_collect_error{collector="autosupport",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewAutosupportExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_autosupport_enabled", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type StorageArray struct {
+	Cache StorageArrayCache `json:"cache"`
+}
+
+type StorageArrayCache struct {
+	CacheBlkSize         float64 `json:"cacheBlkSize"`
+	DemandFlushThreshold float64 `json:"demandFlushThreshold"`
+	DemandFlushAmount    float64 `json:"demandFlushAmount"`
+}
+
+type CacheMetrics struct {
+	Controllers []Controller
+	Cache       StorageArrayCache
+}
+
+type CacheCollector struct {
+	MemorySize         *prometheus.Desc
+	PhysicalMemorySize *prometheus.Desc
+	BlockSize          *prometheus.Desc
+	FlushStart         *prometheus.Desc
+	FlushStop          *prometheus.Desc
+	ctx                context.Context
+	target             config.Target
+	logger             log.Logger
+}
+
+func init() {
+	registerCollector("cache", false, NewCacheExporter)
+}
+
+func NewCacheExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	labels := []string{"controller", "controller_label"}
+	return &CacheCollector{
+		MemorySize: prometheus.NewDesc(prometheus.BuildFQName(namespace, "controller", "cache_memory_bytes"),
+			"Controller cache memory size in bytes", labels, nil),
+		PhysicalMemorySize: prometheus.NewDesc(prometheus.BuildFQName(namespace, "controller", "physical_cache_memory_bytes"),
+			"Controller physical cache memory size in bytes", labels, nil),
+		BlockSize: prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", "block_size_bytes"),
+			"Storage System cache block size in bytes", nil, nil),
+		FlushStart: prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", "flush_start_ratio"),
+			"Ratio of unwritten cache data that starts a demand flush", nil, nil),
+		FlushStop: prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", "flush_stop_ratio"),
+			"Ratio of unwritten cache data that stops a demand flush", nil, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *CacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.MemorySize
+	ch <- c.PhysicalMemorySize
+	ch <- c.BlockSize
+	ch <- c.FlushStart
+	ch <- c.FlushStop
+}
+
+func (c *CacheCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting cache metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	if err == nil {
+		for _, controller := range metrics.Controllers {
+			// Cache memory sizes are reported in MiB
+			ch <- prometheus.MustNewConstMetric(c.MemorySize, prometheus.GaugeValue, controller.CacheMemorySize*1024*1024, controller.ID, controller.PhysicalLocation.Label)
+			ch <- prometheus.MustNewConstMetric(c.PhysicalMemorySize, prometheus.GaugeValue, controller.PhysicalCacheMemorySize*1024*1024, controller.ID, controller.PhysicalLocation.Label)
+		}
+		ch <- prometheus.MustNewConstMetric(c.BlockSize, prometheus.GaugeValue, metrics.Cache.CacheBlkSize)
+		ch <- prometheus.MustNewConstMetric(c.FlushStart, prometheus.GaugeValue, metrics.Cache.DemandFlushThreshold/100)
+		ch <- prometheus.MustNewConstMetric(c.FlushStop, prometheus.GaugeValue, metrics.Cache.DemandFlushAmount/100)
+	}
+
+	collectErrorMetrics(ch, "cache", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "cache")
+}
+
+func (c *CacheCollector) collect() (CacheMetrics, error) {
+	var metrics CacheMetrics
+	var inventory ControllersInventory
+	var arrays []StorageArray
+	var inventoryBody, arraysBody []byte
+	var inventoryErr, arraysErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		arraysBody, arraysErr = getRequestQuery(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/graph/xpath-filter", c.target.Name), "query=/sa", c.logger)
+	}()
+	wg.Wait()
+	if inventoryErr != nil {
+		return metrics, inventoryErr
+	}
+	if arraysErr != nil {
+		return metrics, arraysErr
+	}
+	err := json.Unmarshal(inventoryBody, &inventory)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(arraysBody, &arrays)
+	if err != nil {
+		return metrics, err
+	}
+	if len(arrays) == 0 {
+		return metrics, fmt.Errorf("No storage array returned")
+	}
+	metrics.Controllers = inventory.Controllers
+	metrics.Cache = arrays[0].Cache
+	return metrics, nil
+}
@@ -0,0 +1,148 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestCacheCollector(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/controllers.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	arrayData, err := os.ReadFile("testdata/storage-array.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_cache_block_size_bytes Storage System cache block size in bytes
+	# TYPE eseries_cache_block_size_bytes gauge
+	eseries_cache_block_size_bytes 32768
+	# HELP eseries_cache_flush_start_ratio Ratio of unwritten cache data that starts a demand flush
+	# TYPE eseries_cache_flush_start_ratio gauge
+	eseries_cache_flush_start_ratio 0.8
+	# HELP eseries_cache_flush_stop_ratio Ratio of unwritten cache data that stops a demand flush
+	# TYPE eseries_cache_flush_stop_ratio gauge
+	eseries_cache_flush_stop_ratio 0.8
+	# HELP eseries_controller_cache_memory_bytes Controller cache memory size in bytes
+	# TYPE eseries_controller_cache_memory_bytes gauge
+	eseries_controller_cache_memory_bytes{controller="070000000000000000000001",controller_label="A"} 8.589934592e+09
+	eseries_controller_cache_memory_bytes{controller="070000000000000000000002",controller_label="B"} 8.589934592e+09
+	# HELP eseries_controller_physical_cache_memory_bytes Controller physical cache memory size in bytes
+	# TYPE eseries_controller_physical_cache_memory_bytes gauge
+	eseries_controller_physical_cache_memory_bytes{controller="070000000000000000000001",controller_label="A"} 1.073741824e+10
+	eseries_controller_physical_cache_memory_bytes{controller="070000000000000000000002",controller_label="B"} 1.073741824e+10
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="cache",reason="auth"} 0
+	eseries_exporter_collect_error{collector="cache",reason="connection"} 0
+	eseries_exporter_collect_error{collector="cache",reason="decode"} 0
+	eseries_exporter_collect_error{collector="cache",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="cache",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="cache",reason="other"} 0
+	eseries_exporter_collect_error{collector="cache",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else if req.URL.Query().Get("query") == "/sa" {
+			_, _ = rw.Write(arrayData)
+		} else {
+			http.Error(rw, "error", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewCacheExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 15 {
+		t.Errorf("Unexpected collection count %d, expected 15", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_controller_cache_memory_bytes", "eseries_controller_physical_cache_memory_bytes",
+		"eseries_cache_block_size_bytes", "eseries_cache_flush_start_ratio", "eseries_cache_flush_stop_ratio",
+		"eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestCacheCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="cache",reason="auth"} 0
+	eseries_exporter_collect_error{collector="cache",reason="connection"} 0
+	eseries_exporter_collect_error{collector="cache",reason="decode"} 0
+	eseries_exporter_collect_error{collector="cache",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="cache",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="cache",reason="other"} 0
+	eseries_exporter_collect_error{collector="cache",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewCacheExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_cache_block_size_bytes", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
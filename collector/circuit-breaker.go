@@ -0,0 +1,216 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+var (
+	circuitOpen = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "exporter", "target_circuit_open"),
+		"Collection from the target is suspended after consecutive failures, 1=open 0=closed",
+		[]string{"target"}, nil)
+)
+
+// circuitBreakerIdleTimeout is how long a target can go without being
+// scraped before its circuit breaker is removed
+const circuitBreakerIdleTimeout = time.Hour
+
+// CircuitBreakers holds the circuit breaker of each module and target
+type CircuitBreakers struct {
+	sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// CircuitBreaker stops collection from a target after threshold consecutive
+// scrapes where no request succeeded until a probe request succeeds once
+// cooldown has passed
+type CircuitBreaker struct {
+	sync.Mutex
+	breakers  *CircuitBreakers
+	key       string
+	lastUsed  time.Time
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	open      bool
+	probing   bool
+	openedAt  time.Time
+}
+
+// CircuitBreakerScrape records the results of the requests of a single scrape
+type CircuitBreakerScrape struct {
+	sync.Mutex
+	breaker     *CircuitBreaker
+	reached     bool
+	unreachable bool
+}
+
+type circuitBreakerCollector struct {
+	breaker *CircuitBreaker
+	target  string
+}
+
+func NewCircuitBreakers() *CircuitBreakers {
+	return &CircuitBreakers{breakers: make(map[string]*CircuitBreaker)}
+}
+
+// Get returns the circuit breaker of key, the threshold and cooldown are
+// updated to allow changes from a config reload. A new breaker is only kept
+// once a scrape records a request so scrapes that never send one do not
+// grow the map
+func (c *CircuitBreakers) Get(key string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	c.Lock()
+	b, ok := c.breakers[key]
+	if ok {
+		b.lastUsed = time.Now()
+	}
+	c.Unlock()
+	if !ok {
+		b = &CircuitBreaker{breakers: c, key: key}
+	}
+	b.Lock()
+	b.threshold = threshold
+	b.cooldown = cooldown
+	b.Unlock()
+	return b
+}
+
+// add stores b unless a breaker of its key already exists and returns the
+// stored breaker, breakers of targets no longer scraped are removed
+func (c *CircuitBreakers) add(b *CircuitBreaker) *CircuitBreaker {
+	c.Lock()
+	defer c.Unlock()
+	if existing, ok := c.breakers[b.key]; ok {
+		existing.lastUsed = time.Now()
+		return existing
+	}
+	for key, existing := range c.breakers {
+		if time.Since(existing.lastUsed) > circuitBreakerIdleTimeout {
+			delete(c.breakers, key)
+		}
+	}
+	b.lastUsed = time.Now()
+	c.breakers[b.key] = b
+	return b
+}
+
+// Scrape returns the recorder of the requests of one scrape of the target
+func (b *CircuitBreaker) Scrape() *CircuitBreakerScrape {
+	return &CircuitBreakerScrape{breaker: b}
+}
+
+// Record is called with the result of each request sent to the target,
+// errors that do not indicate the target is unavailable are ignored
+func (s *CircuitBreakerScrape) Record(err error) {
+	s.Lock()
+	defer s.Unlock()
+	if err == nil {
+		s.reached = true
+	} else if targetUnavailable(err) {
+		s.unreachable = true
+	}
+}
+
+// Finish counts the scrape as failed if no request succeeded and at least one
+// found the target unavailable, including requests cut off by the scrape timeout
+func (s *CircuitBreakerScrape) Finish() {
+	s.Lock()
+	reached, unreachable := s.reached, s.unreachable
+	s.Unlock()
+	if !reached && !unreachable {
+		return
+	}
+	b := s.breaker.breakers.add(s.breaker)
+	b.Lock()
+	defer b.Unlock()
+	if reached {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold && !b.open {
+		b.open = true
+		b.openedAt = time.Now()
+	}
+}
+
+// Allow returns true if the target should be collected, once the cooldown
+// has passed a single request is made to decide if the circuit can close
+func (b *CircuitBreaker) Allow(ctx context.Context, target config.Target, logger log.Logger) bool {
+	b.Lock()
+	if !b.open {
+		b.Unlock()
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		b.Unlock()
+		return false
+	}
+	b.probing = true
+	b.Unlock()
+
+	level.Debug(logger).Log("msg", "Probing target with open circuit", "target", target.Name)
+	probe := b.Scrape()
+	target.CircuitBreaker = probe
+	_, err := doRequest(ctx, target, &url.URL{Path: fmt.Sprintf("/devmgr/v2/storage-systems/%s", target.Name)}, logger)
+
+	b.Lock()
+	defer b.Unlock()
+	b.probing = false
+	probe.Lock()
+	defer probe.Unlock()
+	if !probe.reached {
+		// A probe that was not sent or failed for another reason leaves the
+		// cooldown as is
+		if probe.unreachable {
+			level.Error(logger).Log("msg", "Probe of target with open circuit failed", "target", target.Name, "err", err)
+			b.openedAt = time.Now()
+		}
+		return false
+	}
+	level.Info(logger).Log("msg", "Probe of target succeeded, closing circuit", "target", target.Name)
+	b.open = false
+	b.failures = 0
+	return true
+}
+
+func (b *CircuitBreaker) isOpen() bool {
+	b.Lock()
+	defer b.Unlock()
+	return b.open
+}
+
+// Collector returns a collector of the circuit state of target
+func (b *CircuitBreaker) Collector(target string) Collector {
+	return &circuitBreakerCollector{breaker: b, target: target}
+}
+
+func (c *circuitBreakerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- circuitOpen
+}
+
+func (c *circuitBreakerCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(circuitOpen, prometheus.GaugeValue, boolToFloat64(c.breaker.isOpen()), c.target)
+}
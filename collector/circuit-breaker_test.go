@@ -0,0 +1,264 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var down int32 = 1
+	var probes int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/storage-systems/test") {
+			atomic.AddInt32(&probes, 1)
+		}
+		if atomic.LoadInt32(&down) == 1 {
+			conn, _, _ := rw.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	breakers := NewCircuitBreakers()
+	breaker := breakers.Get("default/test", 2, time.Hour)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	ctx := context.Background()
+	expected := `
+	# HELP eseries_exporter_target_circuit_open Collection from the target is suspended after consecutive failures, 1=open 0=closed
+	# TYPE eseries_exporter_target_circuit_open gauge
+	eseries_exporter_target_circuit_open{target="test"} %s
+	`
+	gatherer := setupGatherer(breaker.Collector("test"))
+
+	for i := 0; i < 2; i++ {
+		if !breaker.Allow(ctx, target, logger) {
+			t.Fatalf("Unexpected open circuit after %d failed scrapes", i)
+		}
+		scrape := breaker.Scrape()
+		target.CircuitBreaker = scrape
+		for _, path := range []string{"drives", "volumes"} {
+			if _, err := getRequest(ctx, target, "/devmgr/v2/storage-systems/test/"+path, logger); err == nil {
+				t.Fatalf("Expected error from unreachable target")
+			}
+		}
+		scrape.Finish()
+	}
+	if breaker.Allow(ctx, target, logger) {
+		t.Errorf("Expected open circuit after 2 failed scrapes")
+	}
+	if err := testutil.GatherAndCompare(gatherer, strings.NewReader(strings.Replace(expected, "%s", "1", 1))); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+	if val := atomic.LoadInt32(&probes); val != 0 {
+		t.Errorf("Unexpected probe during cooldown")
+	}
+
+	breaker.Lock()
+	breaker.cooldown = 0
+	breaker.Unlock()
+	if breaker.Allow(ctx, target, logger) {
+		t.Errorf("Expected open circuit after failed probe")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	breaker.Lock()
+	openedAt := breaker.openedAt
+	breaker.Unlock()
+	if breaker.Allow(canceled, target, logger) {
+		t.Errorf("Expected open circuit after canceled probe")
+	}
+	breaker.Lock()
+	if breaker.openedAt != openedAt {
+		t.Errorf("Unexpected cooldown restart after canceled probe")
+	}
+	breaker.Unlock()
+	atomic.StoreInt32(&down, 0)
+	if !breaker.Allow(ctx, target, logger) {
+		t.Errorf("Expected closed circuit after successful probe")
+	}
+	if val := atomic.LoadInt32(&probes); val != 2 {
+		t.Errorf("Unexpected number of probes %d, expected 2", val)
+	}
+	if err := testutil.GatherAndCompare(gatherer, strings.NewReader(strings.Replace(expected, "%s", "0", 1))); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestCircuitBreakerScrapeFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, _, _ := rw.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	breakers := NewCircuitBreakers()
+	breaker := breakers.Get("default/test", 1, time.Hour)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+		Limiter:    config.NewLimiter(server.URL, 1, nil),
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+
+	release, _ := target.Limiter.Acquire(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	scrape := breaker.Scrape()
+	target.CircuitBreaker = scrape
+	if _, err := getRequest(ctx, target, "/devmgr/v2/storage-systems/test/drives", logger); err == nil {
+		t.Fatalf("Expected error waiting for limiter")
+	}
+	scrape.Finish()
+	cancel()
+	release()
+	if breaker.isOpen() {
+		t.Errorf("Unexpected failure recorded for request cut off by the scrape")
+	}
+
+	target.Retries = 1
+	target.RetryBackoff = time.Hour
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	scrape = breaker.Scrape()
+	target.CircuitBreaker = scrape
+	if _, err := getRequest(ctx, target, "/devmgr/v2/storage-systems/test/drives", logger); err == nil {
+		t.Fatalf("Expected error from unreachable target")
+	}
+	scrape.Finish()
+	if !breaker.isOpen() {
+		t.Errorf("Expected failure before retry backoff to be recorded")
+	}
+	if breakers.Get("default/test", 1, time.Hour) != breaker {
+		t.Errorf("Expected circuit breaker kept once a failure is recorded")
+	}
+}
+
+func TestCircuitBreakerUnavailableResponses(t *testing.T) {
+	var status int32 = http.StatusFailedDependency
+	var hang int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.LoadInt32(&hang) == 1 {
+			<-req.Context().Done()
+			return
+		}
+		rw.WriteHeader(int(atomic.LoadInt32(&status)))
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	breakers := NewCircuitBreakers()
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	scrape := func(timeout time.Duration) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		s := breakers.Get("default/test", 2, time.Hour).Scrape()
+		target.CircuitBreaker = s
+		_, _ = getRequest(ctx, target, "/devmgr/v2/storage-systems/test/drives", logger)
+		s.Finish()
+	}
+	failures := func() int {
+		b := breakers.Get("default/test", 2, time.Hour)
+		b.Lock()
+		defer b.Unlock()
+		return b.failures
+	}
+	tests := []struct {
+		Status   int32
+		Hang     int32
+		Expected int
+	}{
+		{Status: http.StatusFailedDependency, Expected: 1},
+		{Status: http.StatusOK, Expected: 0},
+		{Status: http.StatusServiceUnavailable, Expected: 1},
+		{Status: http.StatusNotFound, Expected: 1},
+		{Status: http.StatusOK, Expected: 0},
+		{Hang: 1, Expected: 1},
+	}
+	for i, test := range tests {
+		atomic.StoreInt32(&status, test.Status)
+		atomic.StoreInt32(&hang, test.Hang)
+		scrape(100 * time.Millisecond)
+		if val := failures(); val != test.Expected {
+			t.Errorf("In case %d: unexpected failures %d, expected %d", i, val, test.Expected)
+		}
+	}
+}
+
+func TestCircuitBreakersEvictIdle(t *testing.T) {
+	breakers := NewCircuitBreakers()
+	for _, key := range []string{"default/idle", "default/active"} {
+		s := breakers.Get(key, 1, time.Hour).Scrape()
+		s.Record(nil)
+		s.Finish()
+	}
+	breakers.Lock()
+	breakers.breakers["default/idle"].lastUsed = time.Now().Add(-2 * circuitBreakerIdleTimeout)
+	breakers.Unlock()
+	s := breakers.Get("default/new", 1, time.Hour).Scrape()
+	s.Record(nil)
+	s.Finish()
+	breakers.Lock()
+	defer breakers.Unlock()
+	if _, ok := breakers.breakers["default/idle"]; ok {
+		t.Errorf("Expected idle circuit breaker to be removed")
+	}
+	for _, key := range []string{"default/active", "default/new"} {
+		if _, ok := breakers.breakers[key]; !ok {
+			t.Errorf("Expected circuit breaker %s to be kept", key)
+		}
+	}
+}
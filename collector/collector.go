@@ -14,11 +14,20 @@
 package collector
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -32,14 +41,20 @@ const (
 
 var (
 	collectorState  = make(map[string]bool)
-	factories       = make(map[string]func(target config.Target, logger log.Logger) Collector)
+	factories       = make(map[string]func(ctx context.Context, target config.Target, logger log.Logger) Collector)
+	proxyFactories  = make(map[string]func(ctx context.Context, target config.Target, logger log.Logger) Collector)
 	collectDuration = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "exporter", "collector_duration_seconds"),
 		"Collector time duration.",
 		[]string{"collector"}, nil)
 	collectError = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "exporter", "collect_error"),
-		"Indicates if error has occurred during collection",
+		"Indicates if error has occurred during collection, 1=error of reason 0=otherwise",
+		[]string{"collector", "reason"}, nil)
+	errorReasons   = []string{"auth", "timeout", "http_5xx", "decode", "not_found", "connection", "other"}
+	collectTimeout = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "exporter", "collect_timeout"),
+		"Indicates if the collector was cut off by the scrape timeout",
 		[]string{"collector"}, nil)
 )
 
@@ -49,30 +64,126 @@ type Collector interface {
 	Collect(ch chan<- prometheus.Metric)
 }
 
+// requestError classifies a failed API request
+type requestError struct {
+	reason     string
+	statusCode int
+	err        error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
 type EseriesCollector struct {
 	Collectors map[string]Collector
 }
 
-func registerCollector(collector string, isDefaultEnabled bool, factory func(target config.Target, logger log.Logger) Collector) {
+// timeoutCollector reports if the wrapped collector was still running when
+// the scrape context deadline passed
+type timeoutCollector struct {
+	Collector
+	name string
+	ctx  context.Context
+}
+
+func (c *timeoutCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Collector.Collect(ch)
+	var timedOut float64
+	if errors.Is(c.ctx.Err(), context.DeadlineExceeded) {
+		timedOut = 1
+	}
+	ch <- prometheus.MustNewConstMetric(collectTimeout, prometheus.GaugeValue, timedOut, c.name)
+}
+
+// requestCache fetches each API path at most once, concurrent callers for
+// the same path wait for the first request to complete
+type requestCache struct {
+	sync.Mutex
+	entries map[string]*requestCacheEntry
+}
+
+type requestCacheEntry struct {
+	once sync.Once
+	body []byte
+	err  error
+}
+
+func newRequestCache() *requestCache {
+	return &requestCache{entries: make(map[string]*requestCacheEntry)}
+}
+
+func (r *requestCache) Get(path string, fetch func() ([]byte, error)) ([]byte, error) {
+	r.Lock()
+	entry, ok := r.entries[path]
+	if !ok {
+		entry = &requestCacheEntry{}
+		r.entries[path] = entry
+	}
+	r.Unlock()
+	entry.once.Do(func() {
+		entry.body, entry.err = fetch()
+	})
+	return entry.body, entry.err
+}
+
+func registerCollector(collector string, isDefaultEnabled bool, factory func(ctx context.Context, target config.Target, logger log.Logger) Collector) {
 	collectorState[collector] = isDefaultEnabled
 	factories[collector] = factory
 }
 
-func NewCollector(target config.Target, logger log.Logger) *EseriesCollector {
+func registerProxyCollector(collector string, factory func(ctx context.Context, target config.Target, logger log.Logger) Collector) {
+	proxyFactories[collector] = factory
+}
+
+func NewCollector(ctx context.Context, target config.Target, logger log.Logger) *EseriesCollector {
+	if target.RequestCache == nil {
+		target.RequestCache = newRequestCache()
+	}
 	collectors := make(map[string]Collector)
-	for key, enabled := range collectorState {
-		enable := false
-		if target.Collectors == nil && enabled {
-			enable = true
-		} else if sliceContains(target.Collectors, key) {
-			enable = true
-		}
-		var collector Collector
-		if enable {
-			collector = factories[key](target, log.With(logger, "collector", key, "target", target.Name))
-			collectors[key] = collector
+	for _, key := range enabledCollectors(target.Collectors) {
+		collector := factories[key](ctx, target, log.With(logger, "collector", key, "target", target.Name))
+		collectors[key] = &timeoutCollector{Collector: collector, name: key, ctx: ctx}
+	}
+	return &EseriesCollector{Collectors: collectors}
+}
+
+// Names returns the names of the registered storage system collectors
+func Names() []string {
+	var names []string
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// enabledCollectors returns the configured collectors or the default
+// collectors when none are configured
+func enabledCollectors(configured []string) []string {
+	var enabled []string
+	for key, isDefaultEnabled := range collectorState {
+		if configured == nil && isDefaultEnabled {
+			enabled = append(enabled, key)
+		} else if sliceContains(configured, key) {
+			enabled = append(enabled, key)
 		}
 	}
+	return enabled
+}
+
+// NewProxyCollector returns the collectors that query the Web Services Proxy
+// itself rather than a single storage system
+func NewProxyCollector(ctx context.Context, target config.Target, logger log.Logger) *EseriesCollector {
+	collectors := make(map[string]Collector)
+	for key, factory := range proxyFactories {
+		collector := factory(ctx, target, log.With(logger, "collector", key))
+		collectors[key] = &timeoutCollector{Collector: collector, name: key, ctx: ctx}
+	}
 	return &EseriesCollector{Collectors: collectors}
 }
 
@@ -85,29 +196,189 @@ func sliceContains(slice []string, str string) bool {
 	return false
 }
 
-func getRequest(target config.Target, path string, logger log.Logger) ([]byte, error) {
-	rel := &url.URL{Path: path}
+func boolToFloat64(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// parseTimestamp parses the timestamps returned by the API, which are either
+// epoch seconds or ISO 8601 formatted strings
+func parseTimestamp(value string) (time.Time, error) {
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(epoch, 0), nil
+	}
+	return time.Parse("2006-01-02T15:04:05.000-0700", value)
+}
+
+// collectErrorMetrics emits the collect_error metric of each reason, the
+// reason is empty when collection was successful
+func collectErrorMetrics(ch chan<- prometheus.Metric, collector string, reason string) {
+	for _, r := range errorReasons {
+		var value float64
+		if r == reason {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(collectError, prometheus.GaugeValue, value, collector, r)
+	}
+}
+
+// errorReasonFor returns the collect_error reason of an error returned by a collector
+func errorReasonFor(err error) string {
+	var reqErr *requestError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.reason
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.As(err, &timeErr), errors.As(err, &numErr):
+		return "decode"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return "other"
+}
+
+func statusReason(code int) string {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return "auth"
+	case code == http.StatusNotFound:
+		return "not_found"
+	case code >= 500:
+		return "http_5xx"
+	}
+	return "other"
+}
+
+// transportError classifies errors from the HTTP client, a canceled context
+// is reported as a timeout like a canceled wait for the limiter
+func transportError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &requestError{reason: "timeout", err: err}
+	}
+	return &requestError{reason: "connection", err: err}
+}
+
+// retryable returns true for connection errors, timeouts and responses that
+// indicate the proxy is temporarily unavailable
+func retryable(err error) bool {
+	var reqErr *requestError
+	if !errors.As(err, &reqErr) {
+		return false
+	}
+	switch reqErr.reason {
+	case "connection", "timeout":
+		return true
+	case "http_5xx":
+		return reqErr.statusCode == http.StatusBadGateway || reqErr.statusCode == http.StatusServiceUnavailable || reqErr.statusCode == http.StatusGatewayTimeout
+	}
+	return false
+}
+
+// targetUnavailable returns true for errors that indicate the storage system
+// could not be reached, including the proxy reporting it as unreachable
+func targetUnavailable(err error) bool {
+	var reqErr *requestError
+	if !errors.As(err, &reqErr) {
+		return false
+	}
+	return retryable(err) || reqErr.statusCode == http.StatusFailedDependency
+}
+
+// retryBackoff doubles the backoff for each attempt with jitter of up to half
+func retryBackoff(backoff time.Duration, attempt int) time.Duration {
+	d := backoff << attempt
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func getRequest(ctx context.Context, target config.Target, path string, logger log.Logger) ([]byte, error) {
+	return getRequestQuery(ctx, target, path, "", logger)
+}
+
+// getRequestQuery requests path with the raw query string, the path is
+// escaped so a target name can not change the request
+func getRequestQuery(ctx context.Context, target config.Target, path string, query string, logger log.Logger) ([]byte, error) {
+	rel := &url.URL{Path: path, RawQuery: query}
+	if target.RequestCache != nil {
+		return target.RequestCache.Get(rel.String(), func() ([]byte, error) {
+			return fetchRequest(ctx, target, rel, logger)
+		})
+	}
+	return fetchRequest(ctx, target, rel, logger)
+}
+
+func fetchRequest(ctx context.Context, target config.Target, rel *url.URL, logger log.Logger) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := doRequest(ctx, target, rel, logger)
+		if err == nil || attempt >= target.Retries || ctx.Err() != nil || !retryable(err) {
+			return body, err
+		}
+		backoff := retryBackoff(target.RetryBackoff, attempt)
+		level.Debug(logger).Log("msg", "Retrying request", "path", rel.Path, "attempt", attempt+1, "backoff", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func doRequest(ctx context.Context, target config.Target, rel *url.URL, logger log.Logger) ([]byte, error) {
 	u := target.BaseURL.ResolveReference(rel)
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/json")
 	req.SetBasicAuth(target.User, target.Password)
 
-	level.Debug(logger).Log("msg", "Performing GET request", "url", u.String())
+	release, err := target.Limiter.Acquire(ctx)
+	if err != nil {
+		return nil, &requestError{reason: "timeout", err: err}
+	}
+	defer release()
+	// Requests that never left the exporter say nothing about the target
+	if err := ctx.Err(); err != nil {
+		return nil, transportError(err)
+	}
+	body, err := sendRequest(req, target, rel, logger)
+	if target.CircuitBreaker != nil {
+		target.CircuitBreaker.Record(err)
+	}
+	return body, err
+}
+
+func sendRequest(req *http.Request, target config.Target, rel *url.URL, logger log.Logger) ([]byte, error) {
+	level.Debug(logger).Log("msg", "Performing GET request", "url", req.URL.String())
+	endpoint := endpointTemplate(rel.Path, target.Name)
+	start := time.Now()
 	resp, err := target.HttpClient.Do(req)
 	if err != nil {
-		return nil, err
+		observeRequest(target.Module, endpoint, "error", start, -1)
+		return nil, transportError(err)
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		observeRequest(target.Module, endpoint, "error", start, -1)
+		return nil, transportError(err)
 	}
+	observeRequest(target.Module, endpoint, strconv.Itoa(resp.StatusCode), start, len(body))
 	if resp.StatusCode != http.StatusOK {
 		level.Error(logger).Log("msg", "Response error", "code", resp.StatusCode, "body", body)
-		return nil, fmt.Errorf("%s", body)
+		return nil, &requestError{
+			reason:     statusReason(resp.StatusCode),
+			statusCode: resp.StatusCode,
+			err:        fmt.Errorf("%s returned %s: %s", rel.Path, resp.Status, body),
+		}
 	}
 	return body, nil
 }
@@ -23,7 +23,22 @@
 package collector
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
 )
 
 func setupGatherer(collector Collector) prometheus.Gatherer {
@@ -32,3 +47,161 @@ func setupGatherer(collector Collector) prometheus.Gatherer {
 	gatherers := prometheus.Gatherers{registry}
 	return gatherers
 }
+
+func TestNewCollectorSharesRequests(t *testing.T) {
+	fixtures := make(map[string][]byte)
+	for _, name := range []string{"hardware-inventory", "drive-statistics", "analysed-drive-statistics", "controller-statistics", "analysed-controller-statistics"} {
+		data, err := os.ReadFile("testdata/" + name + ".json")
+		if err != nil {
+			t.Fatalf("Error loading fixture data: %s", err.Error())
+		}
+		fixtures[name] = data
+	}
+	var inventoryRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		name := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+		if name == "hardware-inventory" {
+			atomic.AddInt32(&inventoryRequests, 1)
+		}
+		_, _ = rw.Write(fixtures[name])
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+		Collectors: []string{"drives", "drive-statistics", "controller-statistics", "hardware-inventory"},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	eseriesCollector := NewCollector(context.Background(), target, logger)
+	registry := prometheus.NewRegistry()
+	for _, c := range eseriesCollector.Collectors {
+		registry.MustRegister(c)
+	}
+	if _, err := registry.Gather(); err != nil {
+		t.Errorf("Unexpected error gathering metrics: %s", err.Error())
+	}
+	if val := atomic.LoadInt32(&inventoryRequests); val != 1 {
+		t.Errorf("Unexpected number of hardware-inventory requests, expected 1, got %d", val)
+	}
+
+	eseriesCollector = NewCollector(context.Background(), target, logger)
+	registry = prometheus.NewRegistry()
+	registry.MustRegister(eseriesCollector.Collectors["drives"])
+	if _, err := registry.Gather(); err != nil {
+		t.Errorf("Unexpected error gathering metrics: %s", err.Error())
+	}
+	if val := atomic.LoadInt32(&inventoryRequests); val != 2 {
+		t.Errorf("Unexpected number of hardware-inventory requests after new scrape, expected 2, got %d", val)
+	}
+}
+
+func TestGetRequestRetries(t *testing.T) {
+	tests := []struct {
+		Statuses         []int
+		Retries          int
+		ExpectedRequests int32
+		ExpectedReason   string
+	}{
+		{Statuses: []int{503, 502, 200}, Retries: 2, ExpectedRequests: 3, ExpectedReason: ""},
+		{Statuses: []int{504, 504, 504}, Retries: 1, ExpectedRequests: 2, ExpectedReason: "http_5xx"},
+		{Statuses: []int{500, 200}, Retries: 2, ExpectedRequests: 1, ExpectedReason: "http_5xx"},
+		{Statuses: []int{401, 200}, Retries: 2, ExpectedRequests: 1, ExpectedReason: "auth"},
+		{Statuses: []int{404, 200}, Retries: 2, ExpectedRequests: 1, ExpectedReason: "not_found"},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	for i, test := range tests {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			n := atomic.AddInt32(&requests, 1)
+			rw.WriteHeader(test.Statuses[n-1])
+			_, _ = rw.Write([]byte("{}"))
+		}))
+		baseURL, _ := url.Parse(server.URL)
+		target := config.Target{
+			Name:         "test",
+			User:         "test",
+			Password:     "test",
+			BaseURL:      baseURL,
+			HttpClient:   &http.Client{},
+			Retries:      test.Retries,
+			RetryBackoff: time.Millisecond,
+		}
+		_, err := getRequest(context.Background(), target, "/devmgr/v2/storage-systems/test", logger)
+		var reason string
+		if err != nil {
+			reason = errorReasonFor(err)
+		}
+		if reason != test.ExpectedReason {
+			t.Errorf("In case %d: unexpected reason %q, expected %q", i, reason, test.ExpectedReason)
+		}
+		if val := atomic.LoadInt32(&requests); val != test.ExpectedRequests {
+			t.Errorf("In case %d: unexpected number of requests %d, expected %d", i, val, test.ExpectedRequests)
+		}
+		server.Close()
+	}
+}
+
+func TestGetRequestEscapesTarget(t *testing.T) {
+	var paths []string
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.Path)
+		queries = append(queries, req.URL.RawQuery)
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "abc?x#y%zz",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	if _, err := getRequest(context.Background(), target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", target.Name), logger); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if _, err := getRequestQuery(context.Background(), target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/graph/xpath-filter", target.Name), "query=/sa", logger); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	expectedPaths := []string{
+		"/devmgr/v2/storage-systems/abc?x#y%zz/hardware-inventory",
+		"/devmgr/v2/storage-systems/abc?x#y%zz/graph/xpath-filter",
+	}
+	expectedQueries := []string{"", "query=/sa"}
+	for i := range expectedPaths {
+		if paths[i] != expectedPaths[i] {
+			t.Errorf("Unexpected path %s, expected %s", paths[i], expectedPaths[i])
+		}
+		if queries[i] != expectedQueries[i] {
+			t.Errorf("Unexpected query %q, expected %q", queries[i], expectedQueries[i])
+		}
+	}
+}
+
+func TestErrorReasonFor(t *testing.T) {
+	var v []string
+	if reason := errorReasonFor(json.Unmarshal([]byte("{"), &v)); reason != "decode" {
+		t.Errorf("Unexpected reason %s for invalid JSON, expected decode", reason)
+	}
+	if reason := errorReasonFor(json.Unmarshal([]byte("{}"), &v)); reason != "decode" {
+		t.Errorf("Unexpected reason %s for wrong JSON type, expected decode", reason)
+	}
+	if reason := errorReasonFor(transportError(errors.New("connection refused"))); reason != "connection" {
+		t.Errorf("Unexpected reason %s for connection error, expected connection", reason)
+	}
+	if reason := errorReasonFor(transportError(context.DeadlineExceeded)); reason != "timeout" {
+		t.Errorf("Unexpected reason %s for deadline, expected timeout", reason)
+	}
+	if reason := errorReasonFor(transportError(&url.Error{Op: "Get", URL: "http://test", Err: context.Canceled})); reason != "timeout" {
+		t.Errorf("Unexpected reason %s for canceled request, expected timeout", reason)
+	}
+}
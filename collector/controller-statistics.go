@@ -14,6 +14,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -67,9 +68,16 @@ type ControllersInventory struct {
 }
 
 type Controller struct {
-	ID               string                     `json:"id"`
-	PhysicalLocation ControllerPhysicalLocation `json:"physicalLocation"`
-	Label            string
+	ID                      string                     `json:"id"`
+	PhysicalLocation        ControllerPhysicalLocation `json:"physicalLocation"`
+	HostInterfaces          []HostInterface            `json:"hostInterfaces"`
+	DriveInterfaces         []DriveInterface           `json:"driveInterfaces"`
+	BootTime                string                     `json:"bootTime"`
+	CacheMemorySize         float64                    `json:"cacheMemorySize"`
+	PhysicalCacheMemorySize float64                    `json:"physicalCacheMemorySize"`
+	NetInterfaces           []NetInterface             `json:"netInterfaces"`
+	NetworkSettings         NetworkSettings            `json:"networkSettings"`
+	Label                   string
 }
 
 type ControllerPhysicalLocation struct {
@@ -104,6 +112,7 @@ type ControllerStatisticsCollector struct {
 	DdpBytesTransferred             *prometheus.Desc
 	MaxPossibleBpsUnderCurrentLoad  *prometheus.Desc
 	MaxPossibleIopsUnderCurrentLoad *prometheus.Desc
+	ctx                             context.Context
 	target                          config.Target
 	logger                          log.Logger
 }
@@ -112,7 +121,7 @@ func init() {
 	registerCollector("controller-statistics", true, NewControllerStatisticsExporter)
 }
 
-func NewControllerStatisticsExporter(target config.Target, logger log.Logger) Collector {
+func NewControllerStatisticsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
 	labels := []string{"controller", "controller_label"}
 	return &ControllerStatisticsCollector{
 		AverageReadOpSize: prometheus.NewDesc(prometheus.BuildFQName(namespace, "controller", "average_read_op_size_bytes"),
@@ -169,6 +178,7 @@ func NewControllerStatisticsExporter(target config.Target, logger log.Logger) Co
 			"Controller statistic maxPossibleBpsUnderCurrentLoad", labels, nil),
 		MaxPossibleIopsUnderCurrentLoad: prometheus.NewDesc(prometheus.BuildFQName(namespace, "controller", "max_possible_iops"),
 			"Controller statistic maxPossibleIopsUnderCurrentLoad", labels, nil),
+		ctx:    ctx,
 		target: target,
 		logger: logger,
 	}
@@ -207,11 +217,11 @@ func (c *ControllerStatisticsCollector) Describe(ch chan<- *prometheus.Desc) {
 func (c *ControllerStatisticsCollector) Collect(ch chan<- prometheus.Metric) {
 	level.Debug(c.logger).Log("msg", "Collecting controller-statistics metrics")
 	collectTime := time.Now()
-	var errorMetric int
+	var errorReason string
 	analyzedStatistics, statistics, err := c.collect()
 	if err != nil {
 		level.Error(c.logger).Log("msg", err)
-		errorMetric = 1
+		errorReason = errorReasonFor(err)
 	}
 
 	for _, s := range analyzedStatistics {
@@ -247,7 +257,7 @@ func (c *ControllerStatisticsCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.MaxPossibleIopsUnderCurrentLoad, prometheus.CounterValue, s.MaxPossibleIopsUnderCurrentLoad, s.ID, s.Label)
 	}
 
-	ch <- prometheus.MustNewConstMetric(collectError, prometheus.GaugeValue, float64(errorMetric), "controller-statistics")
+	collectErrorMetrics(ch, "controller-statistics", errorReason)
 	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "controller-statistics")
 }
 
@@ -261,15 +271,15 @@ func (c *ControllerStatisticsCollector) collect() ([]AnalysedControllerStatistic
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		inventoryBody, inventoryErr = getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
 	}()
 	go func() {
 		defer wg.Done()
-		analyzedStatisticsBody, analyzedStatisticsErr = getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/analysed-controller-statistics", c.target.Name), c.logger)
+		analyzedStatisticsBody, analyzedStatisticsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/analysed-controller-statistics", c.target.Name), c.logger)
 	}()
 	go func() {
 		defer wg.Done()
-		statisticsBody, statisticsErr = getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/controller-statistics", c.target.Name), c.logger)
+		statisticsBody, statisticsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/controller-statistics", c.target.Name), c.logger)
 	}()
 	wg.Wait()
 	if inventoryErr != nil {
@@ -23,6 +23,7 @@
 package collector
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -53,9 +54,15 @@ func TestControllerStatisticsCollector(t *testing.T) {
 	# TYPE eseries_controller_average_read_op_size_bytes gauge
 	eseries_controller_average_read_op_size_bytes{controller="070000000000000000000001",controller_label="A"} 39687.27392305163
 	eseries_controller_average_read_op_size_bytes{controller="070000000000000000000002",controller_label="B"} 73664.54585344449
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="controller-statistics"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="auth"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="connection"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="decode"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="other"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
@@ -77,12 +84,12 @@ func TestControllerStatisticsCollector(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewControllerStatisticsExporter(target, logger)
+	collector := NewControllerStatisticsExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 56 {
-		t.Errorf("Unexpected collection count %d, expected 56", val)
+	} else if val != 62 {
+		t.Errorf("Unexpected collection count %d, expected 62", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_controller_average_read_op_size_bytes",
@@ -93,9 +100,15 @@ func TestControllerStatisticsCollector(t *testing.T) {
 
 func TestControllerStatisticsCollectorError(t *testing.T) {
 	expected := `
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="controller-statistics"} 1
+	eseries_exporter_collect_error{collector="controller-statistics",reason="auth"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="connection"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="decode"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="controller-statistics",reason="other"} 0
+	eseries_exporter_collect_error{collector="controller-statistics",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		http.Error(rw, "error", http.StatusNotFound)
@@ -111,12 +124,12 @@ func TestControllerStatisticsCollectorError(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewControllerStatisticsExporter(target, logger)
+	collector := NewControllerStatisticsExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 2 {
-		t.Errorf("Unexpected collection count %d, expected 2", val)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_controller_average_read_op_size_bytes", "eseries_exporter_collect_error"); err != nil {
@@ -0,0 +1,150 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type ArrayDateTime struct {
+	CurrentTime string `json:"currentTime"`
+}
+
+type ControllerTimeMetric struct {
+	Controller      string
+	ControllerLabel string
+	BootTime        time.Time
+}
+
+type ControllerTimeMetrics struct {
+	Controllers []ControllerTimeMetric
+	ArrayTime   time.Time
+	LocalTime   time.Time
+}
+
+type ControllerTimeCollector struct {
+	BootTime  *prometheus.Desc
+	Uptime    *prometheus.Desc
+	ClockSkew *prometheus.Desc
+	ctx       context.Context
+	target    config.Target
+	logger    log.Logger
+}
+
+func init() {
+	registerCollector("controller-time", false, NewControllerTimeExporter)
+}
+
+func NewControllerTimeExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	labels := []string{"controller", "controller_label"}
+	return &ControllerTimeCollector{
+		BootTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "controller", "boot_time_seconds"),
+			"Timestamp of the last controller boot", labels, nil),
+		Uptime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "controller", "uptime_seconds"),
+			"Seconds since the last controller boot according to the storage system clock", labels, nil),
+		ClockSkew: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "clock_skew_seconds"),
+			"Storage system time minus exporter host time", nil, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *ControllerTimeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.BootTime
+	ch <- c.Uptime
+	ch <- c.ClockSkew
+}
+
+func (c *ControllerTimeCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting controller-time metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	if err == nil {
+		for _, m := range metrics.Controllers {
+			ch <- prometheus.MustNewConstMetric(c.BootTime, prometheus.GaugeValue, float64(m.BootTime.Unix()), m.Controller, m.ControllerLabel)
+			ch <- prometheus.MustNewConstMetric(c.Uptime, prometheus.GaugeValue, metrics.ArrayTime.Sub(m.BootTime).Seconds(), m.Controller, m.ControllerLabel)
+		}
+		ch <- prometheus.MustNewConstMetric(c.ClockSkew, prometheus.GaugeValue, metrics.ArrayTime.Sub(metrics.LocalTime).Seconds())
+	}
+
+	collectErrorMetrics(ch, "controller-time", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "controller-time")
+}
+
+func (c *ControllerTimeCollector) collect() (ControllerTimeMetrics, error) {
+	var metrics ControllerTimeMetrics
+	var inventory ControllersInventory
+	var dateTime ArrayDateTime
+	var inventoryBody, dateTimeBody []byte
+	var inventoryErr, dateTimeErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		dateTimeBody, dateTimeErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/date-time", c.target.Name), c.logger)
+		// Record local time as close as possible to when the storage system reported its time
+		metrics.LocalTime = time.Now()
+	}()
+	wg.Wait()
+	if inventoryErr != nil {
+		return metrics, inventoryErr
+	}
+	if dateTimeErr != nil {
+		return metrics, dateTimeErr
+	}
+	err := json.Unmarshal(inventoryBody, &inventory)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(dateTimeBody, &dateTime)
+	if err != nil {
+		return metrics, err
+	}
+	metrics.ArrayTime, err = parseTimestamp(dateTime.CurrentTime)
+	if err != nil {
+		return metrics, err
+	}
+	for _, controller := range inventory.Controllers {
+		bootTime, err := parseTimestamp(controller.BootTime)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.Controllers = append(metrics.Controllers, ControllerTimeMetric{
+			Controller:      controller.ID,
+			ControllerLabel: controller.PhysicalLocation.Label,
+			BootTime:        bootTime,
+		})
+	}
+	return metrics, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+var (
+	driveChannelStatuses = []string{"optimal", "degraded", "failed"}
+)
+
+type DriveInterface struct {
+	InterfaceType string             `json:"interfaceType"`
+	Sas           *SasDriveInterface `json:"sas"`
+}
+
+type SasDriveInterface struct {
+	Channel    int  `json:"channel"`
+	IsDegraded bool `json:"isDegraded"`
+	IocPort    struct {
+		State   string `json:"state"`
+		SasPhys []struct {
+			PhyIdentifier int  `json:"phyIdentifier"`
+			IsOperational bool `json:"isOperational"`
+		} `json:"sasPhys"`
+	} `json:"iocPort"`
+}
+
+type SasPhyStatistics struct {
+	ControllerID               string  `json:"controllerId"`
+	Channel                    int     `json:"channel"`
+	PhyIdentifier              int     `json:"phyIdentifier"`
+	InvalidDwordCount          float64 `json:"invalidDwordCount"`
+	RunningDisparityErrorCount float64 `json:"runningDisparityErrorCount"`
+	LossOfDwordSyncCount       float64 `json:"lossOfDwordSyncCount"`
+	PhyResetProblemCount       float64 `json:"phyResetProblemCount"`
+}
+
+type DriveChannelMetric struct {
+	Controller       string
+	ControllerLabel  string
+	Channel          string
+	Status           string
+	OperationalPhys  float64
+	InvalidDwords    float64
+	DisparityErrors  float64
+	LossOfSync       float64
+	PhyResetProblems float64
+}
+
+type DriveChannelsCollector struct {
+	Status           *prometheus.Desc
+	OperationalPhys  *prometheus.Desc
+	InvalidDwords    *prometheus.Desc
+	DisparityErrors  *prometheus.Desc
+	LossOfSync       *prometheus.Desc
+	PhyResetProblems *prometheus.Desc
+	ctx              context.Context
+	target           config.Target
+	logger           log.Logger
+}
+
+func init() {
+	registerCollector("drive-channels", false, NewDriveChannelsExporter)
+}
+
+func NewDriveChannelsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	labels := []string{"controller", "controller_label", "channel"}
+	return &DriveChannelsCollector{
+		Status: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive_channel", "status"),
+			"Drive channel port status, 1=current status 0=all other states", append(labels, "status"), nil),
+		OperationalPhys: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive_channel", "operational_phys"),
+			"Number of operational SAS PHYs on drive channel port", labels, nil),
+		InvalidDwords: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive_channel", "invalid_dwords_total"),
+			"Invalid dword count of SAS PHYs on drive channel", labels, nil),
+		DisparityErrors: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive_channel", "disparity_errors_total"),
+			"Running disparity error count of SAS PHYs on drive channel", labels, nil),
+		LossOfSync: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive_channel", "loss_of_sync_total"),
+			"Loss of dword synchronization count of SAS PHYs on drive channel", labels, nil),
+		PhyResetProblems: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive_channel", "phy_reset_problems_total"),
+			"PHY reset problem count of SAS PHYs on drive channel", labels, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *DriveChannelsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Status
+	ch <- c.OperationalPhys
+	ch <- c.InvalidDwords
+	ch <- c.DisparityErrors
+	ch <- c.LossOfSync
+	ch <- c.PhyResetProblems
+}
+
+func (c *DriveChannelsCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting drive-channels metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	for _, m := range metrics {
+		for _, status := range driveChannelStatuses {
+			var value float64
+			if status == m.Status {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.Status, prometheus.GaugeValue, value, m.Controller, m.ControllerLabel, m.Channel, status)
+		}
+		var unknown float64
+		if !sliceContains(driveChannelStatuses, m.Status) {
+			unknown = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.Status, prometheus.GaugeValue, unknown, m.Controller, m.ControllerLabel, m.Channel, "unknown")
+		ch <- prometheus.MustNewConstMetric(c.OperationalPhys, prometheus.GaugeValue, m.OperationalPhys, m.Controller, m.ControllerLabel, m.Channel)
+		ch <- prometheus.MustNewConstMetric(c.InvalidDwords, prometheus.CounterValue, m.InvalidDwords, m.Controller, m.ControllerLabel, m.Channel)
+		ch <- prometheus.MustNewConstMetric(c.DisparityErrors, prometheus.CounterValue, m.DisparityErrors, m.Controller, m.ControllerLabel, m.Channel)
+		ch <- prometheus.MustNewConstMetric(c.LossOfSync, prometheus.CounterValue, m.LossOfSync, m.Controller, m.ControllerLabel, m.Channel)
+		ch <- prometheus.MustNewConstMetric(c.PhyResetProblems, prometheus.CounterValue, m.PhyResetProblems, m.Controller, m.ControllerLabel, m.Channel)
+	}
+
+	collectErrorMetrics(ch, "drive-channels", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "drive-channels")
+}
+
+func (c *DriveChannelsCollector) collect() ([]DriveChannelMetric, error) {
+	var inventory ControllersInventory
+	var statistics []SasPhyStatistics
+	var inventoryBody, statisticsBody []byte
+	var inventoryErr, statisticsErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		statisticsBody, statisticsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/sas-phy-statistics", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if inventoryErr != nil {
+		return nil, inventoryErr
+	}
+	if statisticsErr != nil {
+		return nil, statisticsErr
+	}
+	err := json.Unmarshal(inventoryBody, &inventory)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(statisticsBody, &statistics)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []DriveChannelMetric
+	channelIndex := make(map[string]int)
+	for _, controller := range inventory.Controllers {
+		for _, di := range controller.DriveInterfaces {
+			if di.Sas == nil {
+				continue
+			}
+			m := DriveChannelMetric{
+				Controller:      controller.ID,
+				ControllerLabel: controller.PhysicalLocation.Label,
+				Channel:         strconv.Itoa(di.Sas.Channel),
+				Status:          di.Sas.IocPort.State,
+			}
+			for _, phy := range di.Sas.IocPort.SasPhys {
+				if phy.IsOperational {
+					m.OperationalPhys++
+				}
+			}
+			channelIndex[fmt.Sprintf("%s-%d", controller.ID, di.Sas.Channel)] = len(metrics)
+			metrics = append(metrics, m)
+		}
+	}
+	for _, s := range statistics {
+		i, ok := channelIndex[fmt.Sprintf("%s-%d", s.ControllerID, s.Channel)]
+		if !ok {
+			continue
+		}
+		m := &metrics[i]
+		m.InvalidDwords += s.InvalidDwordCount
+		m.DisparityErrors += s.RunningDisparityErrorCount
+		m.LossOfSync += s.LossOfDwordSyncCount
+		m.PhyResetProblems += s.PhyResetProblemCount
+	}
+	return metrics, nil
+}
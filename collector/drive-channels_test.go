@@ -0,0 +1,165 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestDriveChannelsCollector(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/sas-controllers.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	statisticsData, err := os.ReadFile("testdata/sas-phy-statistics.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_drive_channel_invalid_dwords_total Invalid dword count of SAS PHYs on drive channel
+	# TYPE eseries_drive_channel_invalid_dwords_total counter
+	eseries_drive_channel_invalid_dwords_total{channel="1",controller="070000000000000000000001",controller_label="A"} 0
+	eseries_drive_channel_invalid_dwords_total{channel="1",controller="070000000000000000000002",controller_label="B"} 2
+	eseries_drive_channel_invalid_dwords_total{channel="2",controller="070000000000000000000001",controller_label="A"} 0
+	eseries_drive_channel_invalid_dwords_total{channel="2",controller="070000000000000000000002",controller_label="B"} 150
+	# HELP eseries_drive_channel_loss_of_sync_total Loss of dword synchronization count of SAS PHYs on drive channel
+	# TYPE eseries_drive_channel_loss_of_sync_total counter
+	eseries_drive_channel_loss_of_sync_total{channel="1",controller="070000000000000000000001",controller_label="A"} 0
+	eseries_drive_channel_loss_of_sync_total{channel="1",controller="070000000000000000000002",controller_label="B"} 0
+	eseries_drive_channel_loss_of_sync_total{channel="2",controller="070000000000000000000001",controller_label="A"} 0
+	eseries_drive_channel_loss_of_sync_total{channel="2",controller="070000000000000000000002",controller_label="B"} 6
+	# HELP eseries_drive_channel_operational_phys Number of operational SAS PHYs on drive channel port
+	# TYPE eseries_drive_channel_operational_phys gauge
+	eseries_drive_channel_operational_phys{channel="1",controller="070000000000000000000001",controller_label="A"} 4
+	eseries_drive_channel_operational_phys{channel="1",controller="070000000000000000000002",controller_label="B"} 4
+	eseries_drive_channel_operational_phys{channel="2",controller="070000000000000000000001",controller_label="A"} 4
+	eseries_drive_channel_operational_phys{channel="2",controller="070000000000000000000002",controller_label="B"} 3
+	# HELP eseries_drive_channel_status Drive channel port status, 1=current status 0=all other states
+	# TYPE eseries_drive_channel_status gauge
+	eseries_drive_channel_status{channel="1",controller="070000000000000000000001",controller_label="A",status="degraded"} 0
+	eseries_drive_channel_status{channel="1",controller="070000000000000000000001",controller_label="A",status="failed"} 0
+	eseries_drive_channel_status{channel="1",controller="070000000000000000000001",controller_label="A",status="optimal"} 1
+	eseries_drive_channel_status{channel="1",controller="070000000000000000000001",controller_label="A",status="unknown"} 0
+	eseries_drive_channel_status{channel="1",controller="070000000000000000000002",controller_label="B",status="degraded"} 0
+	eseries_drive_channel_status{channel="1",controller="070000000000000000000002",controller_label="B",status="failed"} 0
+	eseries_drive_channel_status{channel="1",controller="070000000000000000000002",controller_label="B",status="optimal"} 1
+	eseries_drive_channel_status{channel="1",controller="070000000000000000000002",controller_label="B",status="unknown"} 0
+	eseries_drive_channel_status{channel="2",controller="070000000000000000000001",controller_label="A",status="degraded"} 0
+	eseries_drive_channel_status{channel="2",controller="070000000000000000000001",controller_label="A",status="failed"} 0
+	eseries_drive_channel_status{channel="2",controller="070000000000000000000001",controller_label="A",status="optimal"} 1
+	eseries_drive_channel_status{channel="2",controller="070000000000000000000001",controller_label="A",status="unknown"} 0
+	eseries_drive_channel_status{channel="2",controller="070000000000000000000002",controller_label="B",status="degraded"} 1
+	eseries_drive_channel_status{channel="2",controller="070000000000000000000002",controller_label="B",status="failed"} 0
+	eseries_drive_channel_status{channel="2",controller="070000000000000000000002",controller_label="B",status="optimal"} 0
+	eseries_drive_channel_status{channel="2",controller="070000000000000000000002",controller_label="B",status="unknown"} 0
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="drive-channels",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="other"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else {
+			_, _ = rw.Write(statisticsData)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewDriveChannelsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 44 {
+		t.Errorf("Unexpected collection count %d, expected 44", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_drive_channel_status", "eseries_drive_channel_operational_phys",
+		"eseries_drive_channel_invalid_dwords_total", "eseries_drive_channel_loss_of_sync_total",
+		"eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestDriveChannelsCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="drive-channels",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="drive-channels",reason="other"} 0
+	eseries_exporter_collect_error{collector="drive-channels",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewDriveChannelsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_drive_channel_status", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
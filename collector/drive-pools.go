@@ -0,0 +1,149 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type StoragePool struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	RaidLevel string `json:"raidLevel"`
+}
+
+type DrivePoolMetric struct {
+	TrayID    string
+	Slot      string
+	Pool      string
+	RaidLevel string
+}
+
+type DrivePoolsCollector struct {
+	Info   *prometheus.Desc
+	ctx    context.Context
+	target config.Target
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("drive-pools", false, NewDrivePoolsExporter)
+}
+
+func NewDrivePoolsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	return &DrivePoolsCollector{
+		Info: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "pool_info"),
+			"Volume group or disk pool the drive is a member of", []string{"tray", "slot", "pool", "raid_level"}, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *DrivePoolsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Info
+}
+
+func (c *DrivePoolsCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting drive-pools metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	for _, m := range metrics {
+		ch <- prometheus.MustNewConstMetric(c.Info, prometheus.GaugeValue, 1, m.TrayID, m.Slot, m.Pool, m.RaidLevel)
+	}
+
+	collectErrorMetrics(ch, "drive-pools", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "drive-pools")
+}
+
+func (c *DrivePoolsCollector) collect() ([]DrivePoolMetric, error) {
+	var inventory DrivesInventory
+	var pools []StoragePool
+	var inventoryBody, poolsBody []byte
+	var inventoryErr, poolsErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		poolsBody, poolsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/storage-pools", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if inventoryErr != nil {
+		return nil, inventoryErr
+	}
+	if poolsErr != nil {
+		return nil, poolsErr
+	}
+	err := json.Unmarshal(inventoryBody, &inventory)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(poolsBody, &pools)
+	if err != nil {
+		return nil, err
+	}
+
+	trays := make(map[string]int)
+	for _, t := range inventory.Trays {
+		trays[t.TrayRef] = t.ID
+	}
+	poolsByID := make(map[string]StoragePool)
+	for _, p := range pools {
+		poolsByID[p.ID] = p
+	}
+	var metrics []DrivePoolMetric
+	var ids []string
+	for _, d := range inventory.Drives {
+		pool, ok := poolsByID[d.VolumeGroupRef]
+		if !ok {
+			continue
+		}
+		if trayId, ok := trays[d.PhysicalLocation.TrayRef]; ok {
+			d.TrayID = strconv.Itoa(trayId)
+		}
+		d.Slot = strconv.Itoa(d.PhysicalLocation.Slot)
+		id := fmt.Sprintf("%s-%s", d.TrayID, d.Slot)
+		if sliceContains(ids, id) {
+			continue
+		}
+		ids = append(ids, id)
+		metrics = append(metrics, DrivePoolMetric{
+			TrayID:    d.TrayID,
+			Slot:      d.Slot,
+			Pool:      pool.Name,
+			RaidLevel: pool.RaidLevel,
+		})
+	}
+	return metrics, nil
+}
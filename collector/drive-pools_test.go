@@ -0,0 +1,131 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestDrivePoolsCollector(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/drives.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	poolsData, err := os.ReadFile("testdata/storage-pools.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_drive_pool_info Volume group or disk pool the drive is a member of
+	# TYPE eseries_drive_pool_info gauge
+	eseries_drive_pool_info{pool="pool0",raid_level="raidDiskPool",slot="53",tray="0"} 1
+	eseries_drive_pool_info{pool="pool1",raid_level="raidDiskPool",slot="58",tray="0"} 1
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="drive-pools",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="other"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else {
+			_, _ = rw.Write(poolsData)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewDrivePoolsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 10 {
+		t.Errorf("Unexpected collection count %d, expected 10", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_drive_pool_info", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestDrivePoolsCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="drive-pools",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="drive-pools",reason="other"} 0
+	eseries_exporter_collect_error{collector="drive-pools",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewDrivePoolsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_drive_pool_info", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
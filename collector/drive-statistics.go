@@ -14,6 +14,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -81,6 +82,7 @@ type DriveStatisticsCollector struct {
 	QueueDepthTotal      *prometheus.Desc
 	RandomIOsTotal       *prometheus.Desc
 	RandomBytesTotal     *prometheus.Desc
+	ctx                  context.Context
 	target               config.Target
 	logger               log.Logger
 }
@@ -89,7 +91,7 @@ func init() {
 	registerCollector("drive-statistics", false, NewDriveStatisticsExporter)
 }
 
-func NewDriveStatisticsExporter(target config.Target, logger log.Logger) Collector {
+func NewDriveStatisticsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
 	return &DriveStatisticsCollector{
 		AverageReadOpSize: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "average_read_op_size_bytes"),
 			"Drive statistic averageReadOpSize", []string{"tray", "slot"}, nil),
@@ -137,6 +139,7 @@ func NewDriveStatisticsExporter(target config.Target, logger log.Logger) Collect
 			"Drive statistic randomIosTotal", []string{"tray", "slot"}, nil),
 		RandomBytesTotal: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "random_bytes_total"),
 			"Drive statistic randomBytesTotal", []string{"tray", "slot"}, nil),
+		ctx:    ctx,
 		target: target,
 		logger: logger,
 	}
@@ -171,11 +174,11 @@ func (c *DriveStatisticsCollector) Describe(ch chan<- *prometheus.Desc) {
 func (c *DriveStatisticsCollector) Collect(ch chan<- prometheus.Metric) {
 	level.Debug(c.logger).Log("msg", "Collecting drive-statistics metrics")
 	collectTime := time.Now()
-	var errorMetric int
+	var errorReason string
 	inventory, analysedDriveStatistics, driveStatistics, err := c.collect()
 	if err != nil {
 		level.Error(c.logger).Log("msg", err)
-		errorMetric = 1
+		errorReason = errorReasonFor(err)
 	}
 
 	trays := make(map[string]int)
@@ -227,7 +230,7 @@ func (c *DriveStatisticsCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.RandomBytesTotal, prometheus.CounterValue, s.RandomBytesTotal, drive.TrayID, drive.Slot)
 	}
 
-	ch <- prometheus.MustNewConstMetric(collectError, prometheus.GaugeValue, float64(errorMetric), "drive-statistics")
+	collectErrorMetrics(ch, "drive-statistics", errorReason)
 	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "drive-statistics")
 }
 
@@ -241,15 +244,15 @@ func (c *DriveStatisticsCollector) collect() (DrivesInventory, []AnalysedDriveSt
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		inventoryBody, inventoryErr = getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
 	}()
 	go func() {
 		defer wg.Done()
-		analyzedStatisticsBody, analyzedStatisticsErr = getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/analysed-drive-statistics", c.target.Name), c.logger)
+		analyzedStatisticsBody, analyzedStatisticsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/analysed-drive-statistics", c.target.Name), c.logger)
 	}()
 	go func() {
 		defer wg.Done()
-		driveStatisticsBody, driveStatisticsErr = getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/drive-statistics", c.target.Name), c.logger)
+		driveStatisticsBody, driveStatisticsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/drive-statistics", c.target.Name), c.logger)
 	}()
 	wg.Wait()
 	if inventoryErr != nil {
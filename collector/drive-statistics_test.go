@@ -23,6 +23,7 @@
 package collector
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -53,9 +54,15 @@ func TestDriveStatisticsCollector(t *testing.T) {
 	# TYPE eseries_drive_average_read_op_size_bytes gauge
 	eseries_drive_average_read_op_size_bytes{slot="58",tray="0"} 39620.99569760295
 	eseries_drive_average_read_op_size_bytes{slot="53",tray="0"} 21312.646464646463
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="drive-statistics"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="other"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
@@ -77,12 +84,12 @@ func TestDriveStatisticsCollector(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewDriveStatisticsExporter(target, logger)
+	collector := NewDriveStatisticsExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 48 {
-		t.Errorf("Unexpected collection count %d, expected 48", val)
+	} else if val != 54 {
+		t.Errorf("Unexpected collection count %d, expected 54", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		/*
@@ -101,9 +108,15 @@ func TestDriveStatisticsCollector(t *testing.T) {
 
 func TestDriveStatisticsCollectorError(t *testing.T) {
 	expected := `
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="drive-statistics"} 1
+	eseries_exporter_collect_error{collector="drive-statistics",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="drive-statistics",reason="other"} 0
+	eseries_exporter_collect_error{collector="drive-statistics",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		http.Error(rw, "error", http.StatusNotFound)
@@ -119,12 +132,12 @@ func TestDriveStatisticsCollectorError(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewDriveStatisticsExporter(target, logger)
+	collector := NewDriveStatisticsExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 2 {
-		t.Errorf("Unexpected collection count %d, expected 2", val)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_drive_average_read_op_size_bytes", "eseries_exporter_collect_error"); err != nil {
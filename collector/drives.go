@@ -14,6 +14,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -50,6 +51,12 @@ type Drive struct {
 	ID               string                `json:"id"`
 	Status           string                `json:"status"`
 	PhysicalLocation DrivePhysicalLocation `json:"physicalLocation"`
+	InterfaceType    DriveInterfaceType    `json:"interfaceType"`
+	FdeCapable       bool                  `json:"fdeCapable"`
+	FdeEnabled       bool                  `json:"fdeEnabled"`
+	FdeLocked        bool                  `json:"fdeLocked"`
+	FipsCapable      bool                  `json:"fipsCapable"`
+	VolumeGroupRef   string                `json:"currentVolumeGroupRef"`
 	TrayID           string
 	Slot             string
 }
@@ -59,20 +66,41 @@ type DrivePhysicalLocation struct {
 	TrayRef string `json:"trayRef"`
 }
 
+type DriveInterfaceType struct {
+	DriveType string `json:"driveType"`
+}
+
 type DrivesCollector struct {
-	Status *prometheus.Desc
-	target config.Target
-	logger log.Logger
+	Status          *prometheus.Desc
+	Info            *prometheus.Desc
+	SecurityCapable *prometheus.Desc
+	SecurityEnabled *prometheus.Desc
+	SecurityLocked  *prometheus.Desc
+	FipsCapable     *prometheus.Desc
+	ctx             context.Context
+	target          config.Target
+	logger          log.Logger
 }
 
 func init() {
 	registerCollector("drives", true, NewDrivesExporter)
 }
 
-func NewDrivesExporter(target config.Target, logger log.Logger) Collector {
+func NewDrivesExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
 	return &DrivesCollector{
 		Status: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "status"),
 			"Drive status", []string{"tray", "slot", "status"}, nil),
+		Info: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "info"),
+			"Drive information", []string{"tray", "slot", "interface_type"}, nil),
+		SecurityCapable: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "security_capable"),
+			"Drive is full disk encryption capable, 1=capable 0=not capable", []string{"tray", "slot"}, nil),
+		SecurityEnabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "security_enabled"),
+			"Drive full disk encryption is enabled, 1=enabled 0=disabled", []string{"tray", "slot"}, nil),
+		SecurityLocked: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "security_locked"),
+			"Drive is locked, 1=locked 0=unlocked", []string{"tray", "slot"}, nil),
+		FipsCapable: prometheus.NewDesc(prometheus.BuildFQName(namespace, "drive", "fips_capable"),
+			"Drive is FIPS capable, 1=capable 0=not capable", []string{"tray", "slot"}, nil),
+		ctx:    ctx,
 		target: target,
 		logger: logger,
 	}
@@ -80,16 +108,21 @@ func NewDrivesExporter(target config.Target, logger log.Logger) Collector {
 
 func (c *DrivesCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Status
+	ch <- c.Info
+	ch <- c.SecurityCapable
+	ch <- c.SecurityEnabled
+	ch <- c.SecurityLocked
+	ch <- c.FipsCapable
 }
 
 func (c *DrivesCollector) Collect(ch chan<- prometheus.Metric) {
 	level.Debug(c.logger).Log("msg", "Collecting drives metrics")
 	collectTime := time.Now()
-	var errorMetric int
+	var errorReason string
 	metrics, err := c.collect()
 	if err != nil {
 		level.Error(c.logger).Log("msg", err)
-		errorMetric = 1
+		errorReason = errorReasonFor(err)
 	}
 
 	trays := make(map[string]int)
@@ -105,7 +138,7 @@ func (c *DrivesCollector) Collect(ch chan<- prometheus.Metric) {
 		id := fmt.Sprintf("%s-%s", d.TrayID, d.Slot)
 		if sliceContains(ids, id) {
 			level.Error(c.logger).Log("msg", "Duplicate drive entry detected, skipping.", "tray", d.TrayID, "slot", d.Slot, "status", d.Status)
-			errorMetric = 1
+			errorReason = "other"
 			continue
 		}
 		ids = append(ids, id)
@@ -121,15 +154,20 @@ func (c *DrivesCollector) Collect(ch chan<- prometheus.Metric) {
 			unknown = 1
 		}
 		ch <- prometheus.MustNewConstMetric(c.Status, prometheus.GaugeValue, unknown, d.TrayID, d.Slot, "unknown")
+		ch <- prometheus.MustNewConstMetric(c.Info, prometheus.GaugeValue, 1, d.TrayID, d.Slot, d.InterfaceType.DriveType)
+		ch <- prometheus.MustNewConstMetric(c.SecurityCapable, prometheus.GaugeValue, boolToFloat64(d.FdeCapable), d.TrayID, d.Slot)
+		ch <- prometheus.MustNewConstMetric(c.SecurityEnabled, prometheus.GaugeValue, boolToFloat64(d.FdeEnabled), d.TrayID, d.Slot)
+		ch <- prometheus.MustNewConstMetric(c.SecurityLocked, prometheus.GaugeValue, boolToFloat64(d.FdeLocked), d.TrayID, d.Slot)
+		ch <- prometheus.MustNewConstMetric(c.FipsCapable, prometheus.GaugeValue, boolToFloat64(d.FipsCapable), d.TrayID, d.Slot)
 	}
 
-	ch <- prometheus.MustNewConstMetric(collectError, prometheus.GaugeValue, float64(errorMetric), "drives")
+	collectErrorMetrics(ch, "drives", errorReason)
 	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "drives")
 }
 
 func (c *DrivesCollector) collect() (DrivesInventory, error) {
 	var metrics DrivesInventory
-	body, err := getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	body, err := getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
 	if err != nil {
 		return metrics, err
 	}
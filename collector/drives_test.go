@@ -23,6 +23,7 @@
 package collector
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -41,6 +42,26 @@ func TestDrivesCollector(t *testing.T) {
 		t.Fatalf("Error loading fixture data: %s", err.Error())
 	}
 	expected := `
+	# HELP eseries_drive_info Drive information
+	# TYPE eseries_drive_info gauge
+	eseries_drive_info{interface_type="sas",slot="53",tray="0"} 1
+	eseries_drive_info{interface_type="sas",slot="58",tray="0"} 1
+	# HELP eseries_drive_fips_capable Drive is FIPS capable, 1=capable 0=not capable
+	# TYPE eseries_drive_fips_capable gauge
+	eseries_drive_fips_capable{slot="53",tray="0"} 0
+	eseries_drive_fips_capable{slot="58",tray="0"} 0
+	# HELP eseries_drive_security_capable Drive is full disk encryption capable, 1=capable 0=not capable
+	# TYPE eseries_drive_security_capable gauge
+	eseries_drive_security_capable{slot="53",tray="0"} 1
+	eseries_drive_security_capable{slot="58",tray="0"} 1
+	# HELP eseries_drive_security_enabled Drive full disk encryption is enabled, 1=enabled 0=disabled
+	# TYPE eseries_drive_security_enabled gauge
+	eseries_drive_security_enabled{slot="53",tray="0"} 1
+	eseries_drive_security_enabled{slot="58",tray="0"} 1
+	# HELP eseries_drive_security_locked Drive is locked, 1=locked 0=unlocked
+	# TYPE eseries_drive_security_locked gauge
+	eseries_drive_security_locked{slot="53",tray="0"} 0
+	eseries_drive_security_locked{slot="58",tray="0"} 0
 	# HELP eseries_drive_status Drive status
 	# TYPE eseries_drive_status gauge
 	eseries_drive_status{slot="53",status="bypassed",tray="0"} 0
@@ -67,9 +88,15 @@ func TestDrivesCollector(t *testing.T) {
 	eseries_drive_status{slot="58",status="unknown",tray="0"} 0
 	eseries_drive_status{slot="58",status="unresponsive",tray="0"} 0
 	eseries_drive_status{slot="58",status="__UNDEFINED",tray="0"} 0
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="drives"} 0
+	eseries_exporter_collect_error{collector="drives",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drives",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drives",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drives",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drives",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="drives",reason="other"} 0
+	eseries_exporter_collect_error{collector="drives",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		_, _ = rw.Write(fixtureData)
@@ -85,15 +112,16 @@ func TestDrivesCollector(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewDrivesExporter(target, logger)
+	collector := NewDrivesExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 26 {
-		t.Errorf("Unexpected collection count %d, expected 26", val)
+	} else if val != 42 {
+		t.Errorf("Unexpected collection count %d, expected 42", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
-		"eseries_drive_status", "eseries_exporter_collect_error"); err != nil {
+		"eseries_drive_status", "eseries_drive_info", "eseries_drive_security_capable", "eseries_drive_security_enabled",
+		"eseries_drive_security_locked", "eseries_drive_fips_capable", "eseries_exporter_collect_error"); err != nil {
 		t.Errorf("unexpected collecting result:\n%s", err)
 	}
 }
@@ -130,9 +158,15 @@ func TestDrivesCollectorDuplicates(t *testing.T) {
 	eseries_drive_status{slot="58",status="unknown",tray="0"} 0
 	eseries_drive_status{slot="58",status="unresponsive",tray="0"} 0
 	eseries_drive_status{slot="58",status="__UNDEFINED",tray="0"} 0
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="drives"} 1
+	eseries_exporter_collect_error{collector="drives",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drives",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drives",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drives",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drives",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="drives",reason="other"} 1
+	eseries_exporter_collect_error{collector="drives",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		_, _ = rw.Write(fixtureData)
@@ -148,12 +182,12 @@ func TestDrivesCollectorDuplicates(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewDrivesExporter(target, logger)
+	collector := NewDrivesExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 26 {
-		t.Errorf("Unexpected collection count %d, expected 26", val)
+	} else if val != 42 {
+		t.Errorf("Unexpected collection count %d, expected 42", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_drive_status", "eseries_exporter_collect_error"); err != nil {
@@ -161,11 +195,64 @@ func TestDrivesCollectorDuplicates(t *testing.T) {
 	}
 }
 
+func TestDrivesCollectorNvme(t *testing.T) {
+	fixtureData, err := os.ReadFile("testdata/nvme-hardware-inventory.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_drive_info Drive information
+	# TYPE eseries_drive_info gauge
+	eseries_drive_info{interface_type="nvme",slot="1",tray="99"} 1
+	eseries_drive_info{interface_type="nvme",slot="2",tray="99"} 1
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="drives",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drives",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drives",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drives",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drives",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="drives",reason="other"} 0
+	eseries_exporter_collect_error{collector="drives",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(fixtureData)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewDrivesExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 42 {
+		t.Errorf("Unexpected collection count %d, expected 42", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_drive_info", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
 func TestDrivesCollectorError(t *testing.T) {
 	expected := `
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="drives"} 1
+	eseries_exporter_collect_error{collector="drives",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drives",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drives",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drives",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drives",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="drives",reason="other"} 0
+	eseries_exporter_collect_error{collector="drives",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		http.Error(rw, "error", http.StatusNotFound)
@@ -181,12 +268,12 @@ func TestDrivesCollectorError(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewDrivesExporter(target, logger)
+	collector := NewDrivesExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 2 {
-		t.Errorf("Unexpected collection count %d, expected 2", val)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_drive_status", "eseries_exporter_collect_error"); err != nil {
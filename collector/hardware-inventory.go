@@ -14,6 +14,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -99,6 +100,7 @@ type HardwareInventoryCollector struct {
 	PowerSupplyStatus     *prometheus.Desc
 	CacheMemoryDimmStatus *prometheus.Desc
 	ThermalSensorStatus   *prometheus.Desc
+	ctx                   context.Context
 	target                config.Target
 	logger                log.Logger
 }
@@ -107,7 +109,7 @@ func init() {
 	registerCollector("hardware-inventory", true, NewHardwareInventoryExporter)
 }
 
-func NewHardwareInventoryExporter(target config.Target, logger log.Logger) Collector {
+func NewHardwareInventoryExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
 	return &HardwareInventoryCollector{
 		BatteryStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, "battery", "status"),
 			"Status of battery hardware device", []string{"tray", "slot", "status"}, nil),
@@ -119,6 +121,7 @@ func NewHardwareInventoryExporter(target config.Target, logger log.Logger) Colle
 			"Status of cache memory DIMM hardware device", []string{"tray", "slot", "status"}, nil),
 		ThermalSensorStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, "thermal_sensor", "status"),
 			"Status of thermal sensor hardware device", []string{"tray", "slot", "status"}, nil),
+		ctx:    ctx,
 		target: target,
 		logger: logger,
 	}
@@ -135,11 +138,11 @@ func (c *HardwareInventoryCollector) Describe(ch chan<- *prometheus.Desc) {
 func (c *HardwareInventoryCollector) Collect(ch chan<- prometheus.Metric) {
 	level.Debug(c.logger).Log("msg", "Collecting hardware-inventory metrics")
 	collectTime := time.Now()
-	var errorMetric int
+	var errorReason string
 	inventory, err := c.collect()
 	if err != nil {
 		level.Error(c.logger).Log("msg", err)
-		errorMetric = 1
+		errorReason = errorReasonFor(err)
 	}
 
 	trays := make(map[string]int)
@@ -237,13 +240,13 @@ func (c *HardwareInventoryCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.ThermalSensorStatus, prometheus.GaugeValue, unknown, d.TrayID, d.Slot, "unknown")
 	}
 
-	ch <- prometheus.MustNewConstMetric(collectError, prometheus.GaugeValue, float64(errorMetric), "hardware-inventory")
+	collectErrorMetrics(ch, "hardware-inventory", errorReason)
 	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "hardware-inventory")
 }
 
 func (c *HardwareInventoryCollector) collect() (HardwareInventory, error) {
 	var inventory HardwareInventory
-	body, err := getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	body, err := getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
 	if err != nil {
 		return inventory, err
 	}
@@ -23,6 +23,7 @@
 package collector
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -113,9 +114,15 @@ func TestHardwareInventoryCollector(t *testing.T) {
 	eseries_thermal_sensor_status{slot="2",status="optimal",tray="99"} 0
 	eseries_thermal_sensor_status{slot="2",status="removed",tray="99"} 0
 	eseries_thermal_sensor_status{slot="2",status="unknown",tray="99"} 1
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="hardware-inventory"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="auth"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="connection"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="decode"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="other"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		_, _ = rw.Write(fixtureData)
@@ -131,12 +138,12 @@ func TestHardwareInventoryCollector(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewHardwareInventoryExporter(target, logger)
+	collector := NewHardwareInventoryExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 64 {
-		t.Errorf("Unexpected collection count %d, expected 64", val)
+	} else if val != 70 {
+		t.Errorf("Unexpected collection count %d, expected 70", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_battery_status", "eseries_fan_status",
@@ -148,9 +155,15 @@ func TestHardwareInventoryCollector(t *testing.T) {
 
 func TestHardwareInventoryCollectorError(t *testing.T) {
 	expected := `
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="hardware-inventory"} 1
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="auth"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="connection"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="decode"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="other"} 0
+	eseries_exporter_collect_error{collector="hardware-inventory",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		http.Error(rw, "error", http.StatusNotFound)
@@ -166,12 +179,12 @@ func TestHardwareInventoryCollectorError(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewHardwareInventoryExporter(target, logger)
+	collector := NewHardwareInventoryExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 2 {
-		t.Errorf("Unexpected collection count %d, expected 2", val)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_battery_status", "eseries_exporter_collect_error"); err != nil {
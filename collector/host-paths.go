@@ -0,0 +1,280 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type FibreInterface struct {
+	Channel    int    `json:"channel"`
+	LinkStatus string `json:"linkStatus"`
+}
+
+type SasInterface struct {
+	Channel int `json:"channel"`
+	IocPort struct {
+		SasPhys []struct {
+			IsOperational bool `json:"isOperational"`
+		} `json:"sasPhys"`
+	} `json:"iocPort"`
+}
+
+type HostPathMetric struct {
+	Host            string
+	Controller      string
+	ControllerLabel string
+	Paths           float64
+}
+
+type HostPathsCollector struct {
+	PathRedundancy            *prometheus.Desc
+	SingleControllerReachable *prometheus.Desc
+	ctx                       context.Context
+	target                    config.Target
+	logger                    log.Logger
+}
+
+func init() {
+	registerCollector("host-paths", false, NewHostPathsExporter)
+}
+
+func NewHostPathsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	return &HostPathsCollector{
+		PathRedundancy: prometheus.NewDesc(prometheus.BuildFQName(namespace, "host", "path_redundancy"),
+			"Number of controller ports with a path from the host", []string{"host", "controller", "controller_label"}, nil),
+		SingleControllerReachable: prometheus.NewDesc(prometheus.BuildFQName(namespace, "host", "single_controller_reachable"),
+			"Host has paths to at most one controller, 1=one or no controllers 0=otherwise", []string{"host"}, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *HostPathsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.PathRedundancy
+	ch <- c.SingleControllerReachable
+}
+
+func (c *HostPathsCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting host-paths metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	reachable := make(map[string]int)
+	var hosts []string
+	for _, m := range metrics {
+		ch <- prometheus.MustNewConstMetric(c.PathRedundancy, prometheus.GaugeValue, m.Paths, m.Host, m.Controller, m.ControllerLabel)
+		if !sliceContains(hosts, m.Host) {
+			hosts = append(hosts, m.Host)
+		}
+		if m.Paths > 0 {
+			reachable[m.Host]++
+		}
+	}
+	for _, host := range hosts {
+		var single float64
+		if reachable[host] <= 1 {
+			single = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.SingleControllerReachable, prometheus.GaugeValue, single, host)
+	}
+
+	collectErrorMetrics(ch, "host-paths", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "host-paths")
+}
+
+func (c *HostPathsCollector) collect() ([]HostPathMetric, error) {
+	var inventory ControllersInventory
+	var hosts []Host
+	var sessions []IscsiSession
+	var connections []NvmeofConnection
+	var inventoryBody, hostsBody []byte
+	var inventoryErr, hostsErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		hostsBody, hostsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hosts", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if inventoryErr != nil {
+		return nil, inventoryErr
+	}
+	if hostsErr != nil {
+		return nil, hostsErr
+	}
+	err := json.Unmarshal(inventoryBody, &inventory)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(hostsBody, &hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions and connections are only requested for the interface types of
+	// the hosts so systems without iSCSI or NVMe-oF do not fail collection
+	var hasIscsi, hasNvmeof bool
+	for _, h := range hosts {
+		for _, i := range h.Initiators {
+			switch i.NodeName.IoInterfaceType {
+			case "iscsi":
+				hasIscsi = true
+			case "nvmeof":
+				hasNvmeof = true
+			}
+		}
+	}
+	var sessionsBody, connectionsBody []byte
+	var sessionsErr, connectionsErr error
+	if hasIscsi {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sessionsBody, sessionsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/iscsi/sessions", c.target.Name), c.logger)
+		}()
+	}
+	if hasNvmeof {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			connectionsBody, connectionsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/nvmeof/connections", c.target.Name), c.logger)
+		}()
+	}
+	wg.Wait()
+	if sessionsErr != nil {
+		return nil, sessionsErr
+	}
+	if connectionsErr != nil {
+		return nil, connectionsErr
+	}
+	if hasIscsi {
+		err = json.Unmarshal(sessionsBody, &sessions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if hasNvmeof {
+		err = json.Unmarshal(connectionsBody, &connections)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Ports of each controller that have a connection from each initiator,
+	// ports are identified by interface type and channel
+	initiatorPorts := make(map[string]map[string][]string)
+	addPort := func(initiator string, controller string, port string) {
+		if _, ok := initiatorPorts[initiator]; !ok {
+			initiatorPorts[initiator] = make(map[string][]string)
+		}
+		initiatorPorts[initiator][controller] = append(initiatorPorts[initiator][controller], port)
+	}
+	for _, s := range sessions {
+		for _, conn := range s.Connections {
+			addPort("iscsi/"+s.InitiatorName, conn.ControllerID, fmt.Sprintf("iscsi/%d", conn.Channel))
+		}
+	}
+	for _, conn := range connections {
+		addPort("nvmeof/"+conn.HostNqn, conn.ControllerID, fmt.Sprintf("nvmeof/%d", conn.Channel))
+	}
+	// FC and SAS logins are not reported by the proxy, host ports of the
+	// type with an active link are paths available to all hosts of the type
+	linkPorts := make(map[string]map[string][]string)
+	for _, controller := range inventory.Controllers {
+		for _, i := range controller.HostInterfaces {
+			var port string
+			switch {
+			case i.InterfaceType == "fc" && i.Fibre != nil && i.Fibre.LinkStatus == "up":
+				port = fmt.Sprintf("fc/%d", i.Fibre.Channel)
+			case i.InterfaceType == "sas" && i.Sas != nil && sasLinkUp(i.Sas):
+				port = fmt.Sprintf("sas/%d", i.Sas.Channel)
+			default:
+				continue
+			}
+			if _, ok := linkPorts[i.InterfaceType]; !ok {
+				linkPorts[i.InterfaceType] = make(map[string][]string)
+			}
+			linkPorts[i.InterfaceType][controller.ID] = append(linkPorts[i.InterfaceType][controller.ID], port)
+		}
+	}
+
+	var metrics []HostPathMetric
+	for _, h := range hosts {
+		var supported bool
+		hostPorts := make(map[string][]string)
+		for _, i := range h.Initiators {
+			var ports map[string][]string
+			switch i.NodeName.IoInterfaceType {
+			case "iscsi":
+				ports = initiatorPorts["iscsi/"+i.NodeName.IscsiNodeName]
+			case "nvmeof":
+				ports = initiatorPorts["nvmeof/"+i.NodeName.NvmeNodeName]
+			case "fc", "sas":
+				ports = linkPorts[i.NodeName.IoInterfaceType]
+			default:
+				continue
+			}
+			supported = true
+			for controller, controllerPorts := range ports {
+				for _, port := range controllerPorts {
+					if !sliceContains(hostPorts[controller], port) {
+						hostPorts[controller] = append(hostPorts[controller], port)
+					}
+				}
+			}
+		}
+		if !supported {
+			level.Debug(c.logger).Log("msg", "Skipping host without iSCSI, NVMe-oF, FC or SAS initiators", "host", h.Name)
+			continue
+		}
+		for _, controller := range inventory.Controllers {
+			metrics = append(metrics, HostPathMetric{
+				Host:            h.Name,
+				Controller:      controller.ID,
+				ControllerLabel: controller.PhysicalLocation.Label,
+				Paths:           float64(len(hostPorts[controller.ID])),
+			})
+		}
+	}
+	return metrics, nil
+}
+
+func sasLinkUp(i *SasInterface) bool {
+	for _, phy := range i.IocPort.SasPhys {
+		if phy.IsOperational {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,269 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestHostPathsCollector(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/iscsi-controllers.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	hostsData, err := os.ReadFile("testdata/hosts.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	sessionsData, err := os.ReadFile("testdata/iscsi-sessions.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="host-paths",reason="auth"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="connection"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="decode"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="other"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="timeout"} 0
+	# HELP eseries_host_path_redundancy Number of controller ports with a path from the host
+	# TYPE eseries_host_path_redundancy gauge
+	eseries_host_path_redundancy{controller="070000000000000000000001",controller_label="A",host="host1"} 1
+	eseries_host_path_redundancy{controller="070000000000000000000001",controller_label="A",host="host2"} 2
+	eseries_host_path_redundancy{controller="070000000000000000000001",controller_label="A",host="host3"} 0
+	eseries_host_path_redundancy{controller="070000000000000000000002",controller_label="B",host="host1"} 1
+	eseries_host_path_redundancy{controller="070000000000000000000002",controller_label="B",host="host2"} 0
+	eseries_host_path_redundancy{controller="070000000000000000000002",controller_label="B",host="host3"} 0
+	# HELP eseries_host_single_controller_reachable Host has paths to at most one controller, 1=one or no controllers 0=otherwise
+	# TYPE eseries_host_single_controller_reachable gauge
+	eseries_host_single_controller_reachable{host="host1"} 0
+	eseries_host_single_controller_reachable{host="host2"} 1
+	eseries_host_single_controller_reachable{host="host3"} 1
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else if strings.HasSuffix(req.URL.Path, "hosts") {
+			_, _ = rw.Write(hostsData)
+		} else {
+			_, _ = rw.Write(sessionsData)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewHostPathsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 17 {
+		t.Errorf("Unexpected collection count %d, expected 17", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_host_path_redundancy", "eseries_host_single_controller_reachable",
+		"eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestHostPathsCollectorNvmeof(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/nvme-hardware-inventory.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	hostsData, err := os.ReadFile("testdata/nvme-hosts.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	connectionsData, err := os.ReadFile("testdata/nvmeof-connections.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_host_path_redundancy Number of controller ports with a path from the host
+	# TYPE eseries_host_path_redundancy gauge
+	eseries_host_path_redundancy{controller="070000000000000000000001",controller_label="A",host="ef1"} 1
+	eseries_host_path_redundancy{controller="070000000000000000000001",controller_label="A",host="ef2"} 2
+	eseries_host_path_redundancy{controller="070000000000000000000001",controller_label="A",host="ef3"} 0
+	eseries_host_path_redundancy{controller="070000000000000000000002",controller_label="B",host="ef1"} 1
+	eseries_host_path_redundancy{controller="070000000000000000000002",controller_label="B",host="ef2"} 0
+	eseries_host_path_redundancy{controller="070000000000000000000002",controller_label="B",host="ef3"} 0
+	# HELP eseries_host_single_controller_reachable Host has paths to at most one controller, 1=one or no controllers 0=otherwise
+	# TYPE eseries_host_single_controller_reachable gauge
+	eseries_host_single_controller_reachable{host="ef1"} 0
+	eseries_host_single_controller_reachable{host="ef2"} 1
+	eseries_host_single_controller_reachable{host="ef3"} 1
+	`
+	var sessionRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else if strings.HasSuffix(req.URL.Path, "hosts") {
+			_, _ = rw.Write(hostsData)
+		} else if strings.HasSuffix(req.URL.Path, "nvmeof/connections") {
+			_, _ = rw.Write(connectionsData)
+		} else {
+			sessionRequests++
+			http.Error(rw, "error", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewHostPathsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 17 {
+		t.Errorf("Unexpected collection count %d, expected 17", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_host_path_redundancy", "eseries_host_single_controller_reachable"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+	if sessionRequests != 0 {
+		t.Errorf("Unexpected iSCSI sessions request without iSCSI hosts")
+	}
+}
+
+func TestHostPathsCollectorFcSas(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/fc-sas-controllers.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	hostsData, err := os.ReadFile("testdata/fc-sas-hosts.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_host_path_redundancy Number of controller ports with a path from the host
+	# TYPE eseries_host_path_redundancy gauge
+	eseries_host_path_redundancy{controller="070000000000000000000001",controller_label="A",host="fchost"} 2
+	eseries_host_path_redundancy{controller="070000000000000000000001",controller_label="A",host="sashost"} 1
+	eseries_host_path_redundancy{controller="070000000000000000000002",controller_label="B",host="fchost"} 1
+	eseries_host_path_redundancy{controller="070000000000000000000002",controller_label="B",host="sashost"} 0
+	# HELP eseries_host_single_controller_reachable Host has paths to at most one controller, 1=one or no controllers 0=otherwise
+	# TYPE eseries_host_single_controller_reachable gauge
+	eseries_host_single_controller_reachable{host="fchost"} 0
+	eseries_host_single_controller_reachable{host="sashost"} 1
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else if strings.HasSuffix(req.URL.Path, "hosts") {
+			_, _ = rw.Write(hostsData)
+		} else {
+			http.Error(rw, "error", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewHostPathsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 14 {
+		t.Errorf("Unexpected collection count %d, expected 14", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_host_path_redundancy", "eseries_host_single_controller_reachable"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestHostPathsCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="host-paths",reason="auth"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="connection"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="decode"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="host-paths",reason="other"} 0
+	eseries_exporter_collect_error{collector="host-paths",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewHostPathsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_host_path_redundancy", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+var (
+	iscsiAuthMethods = []string{"none", "chap"}
+)
+
+type HostInterface struct {
+	InterfaceType string           `json:"interfaceType"`
+	Iscsi         *IscsiInterface  `json:"iscsi"`
+	Nvmeof        *NvmeofInterface `json:"nvmeof"`
+	Fibre         *FibreInterface  `json:"fibre"`
+	Sas           *SasInterface    `json:"sas"`
+}
+
+type IscsiInterface struct {
+	ID           string `json:"id"`
+	ControllerID string `json:"controllerId"`
+	Channel      int    `json:"channel"`
+}
+
+type Host struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	ClusterRef string          `json:"clusterRef"`
+	Initiators []HostInitiator `json:"initiators"`
+}
+
+type HostInitiator struct {
+	ID       string                `json:"id"`
+	Label    string                `json:"label"`
+	NodeName HostInitiatorNodeName `json:"nodeName"`
+}
+
+type HostInitiatorNodeName struct {
+	IoInterfaceType string `json:"ioInterfaceType"`
+	IscsiNodeName   string `json:"iscsiNodeName"`
+	NvmeNodeName    string `json:"nvmeNodeName"`
+}
+
+type IscsiSession struct {
+	InitiatorName string            `json:"initiatorName"`
+	Connections   []IscsiConnection `json:"connections"`
+}
+
+type IscsiConnection struct {
+	ControllerID string `json:"controllerId"`
+	Channel      int    `json:"channel"`
+}
+
+type IscsiTargetSettings struct {
+	NodeName struct {
+		IscsiNodeName string `json:"iscsiNodeName"`
+	} `json:"nodeName"`
+	Alias struct {
+		IscsiAlias string `json:"iscsiAlias"`
+	} `json:"alias"`
+	Auth struct {
+		AuthMethod string `json:"authMethod"`
+	} `json:"auth"`
+	Portals []IscsiPortal `json:"portals"`
+}
+
+type IscsiPortal struct {
+	GroupTag  int `json:"groupTag"`
+	IPAddress struct {
+		AddressType string `json:"addressType"`
+		IPv4Address string `json:"ipv4Address"`
+		IPv6Address string `json:"ipv6Address"`
+	} `json:"ipAddress"`
+	TCPListenPort int `json:"tcpListenPort"`
+}
+
+type IscsiInitiatorMetric struct {
+	Host      string
+	Initiator string
+	Sessions  float64
+}
+
+type IscsiPortMetric struct {
+	Controller      string
+	ControllerLabel string
+	Port            string
+	Sessions        float64
+}
+
+type IscsiMetrics struct {
+	Initiators     []IscsiInitiatorMetric
+	Ports          []IscsiPortMetric
+	TargetSettings IscsiTargetSettings
+}
+
+type IscsiCollector struct {
+	InitiatorSessions *prometheus.Desc
+	PortSessions      *prometheus.Desc
+	TargetInfo        *prometheus.Desc
+	TargetAuthMethod  *prometheus.Desc
+	TargetPortalInfo  *prometheus.Desc
+	ctx               context.Context
+	target            config.Target
+	logger            log.Logger
+}
+
+func init() {
+	registerCollector("iscsi", false, NewIscsiExporter)
+}
+
+func NewIscsiExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	return &IscsiCollector{
+		InitiatorSessions: prometheus.NewDesc(prometheus.BuildFQName(namespace, "iscsi_initiator", "sessions"),
+			"Number of active iSCSI sessions for host initiator", []string{"host", "initiator"}, nil),
+		PortSessions: prometheus.NewDesc(prometheus.BuildFQName(namespace, "iscsi_port", "sessions"),
+			"Number of active iSCSI sessions on controller port", []string{"controller", "controller_label", "port"}, nil),
+		TargetInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, "iscsi_target", "info"),
+			"iSCSI target information", []string{"iqn", "alias"}, nil),
+		TargetAuthMethod: prometheus.NewDesc(prometheus.BuildFQName(namespace, "iscsi_target", "authentication_method"),
+			"iSCSI target authentication method", []string{"method"}, nil),
+		TargetPortalInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, "iscsi_target", "portal_info"),
+			"iSCSI target portal", []string{"group_tag", "address", "port"}, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *IscsiCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.InitiatorSessions
+	ch <- c.PortSessions
+	ch <- c.TargetInfo
+	ch <- c.TargetAuthMethod
+	ch <- c.TargetPortalInfo
+}
+
+func (c *IscsiCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting iscsi metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	if err == nil {
+		for _, i := range metrics.Initiators {
+			ch <- prometheus.MustNewConstMetric(c.InitiatorSessions, prometheus.GaugeValue, i.Sessions, i.Host, i.Initiator)
+		}
+		for _, p := range metrics.Ports {
+			ch <- prometheus.MustNewConstMetric(c.PortSessions, prometheus.GaugeValue, p.Sessions, p.Controller, p.ControllerLabel, p.Port)
+		}
+		settings := metrics.TargetSettings
+		ch <- prometheus.MustNewConstMetric(c.TargetInfo, prometheus.GaugeValue, 1, settings.NodeName.IscsiNodeName, settings.Alias.IscsiAlias)
+		for _, method := range iscsiAuthMethods {
+			var value float64
+			if method == settings.Auth.AuthMethod {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.TargetAuthMethod, prometheus.GaugeValue, value, method)
+		}
+		var unknown float64
+		if !sliceContains(iscsiAuthMethods, settings.Auth.AuthMethod) {
+			unknown = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.TargetAuthMethod, prometheus.GaugeValue, unknown, "unknown")
+		for _, p := range settings.Portals {
+			address := p.IPAddress.IPv4Address
+			if p.IPAddress.AddressType == "ipv6" {
+				address = p.IPAddress.IPv6Address
+			}
+			ch <- prometheus.MustNewConstMetric(c.TargetPortalInfo, prometheus.GaugeValue, 1, strconv.Itoa(p.GroupTag), address, strconv.Itoa(p.TCPListenPort))
+		}
+	}
+
+	collectErrorMetrics(ch, "iscsi", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "iscsi")
+}
+
+func (c *IscsiCollector) collect() (IscsiMetrics, error) {
+	var metrics IscsiMetrics
+	var inventory ControllersInventory
+	var hosts []Host
+	var sessions []IscsiSession
+	var inventoryBody, hostsBody, sessionsBody, settingsBody []byte
+	var inventoryErr, hostsErr, sessionsErr, settingsErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		hostsBody, hostsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hosts", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		sessionsBody, sessionsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/iscsi/sessions", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		settingsBody, settingsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/iscsi/target-settings", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if inventoryErr != nil {
+		return metrics, inventoryErr
+	}
+	if hostsErr != nil {
+		return metrics, hostsErr
+	}
+	if sessionsErr != nil {
+		return metrics, sessionsErr
+	}
+	if settingsErr != nil {
+		return metrics, settingsErr
+	}
+	err := json.Unmarshal(inventoryBody, &inventory)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(hostsBody, &hosts)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(sessionsBody, &sessions)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(settingsBody, &metrics.TargetSettings)
+	if err != nil {
+		return metrics, err
+	}
+
+	initiatorSessions := make(map[string]float64)
+	portSessions := make(map[string]float64)
+	for _, s := range sessions {
+		initiatorSessions[s.InitiatorName]++
+		var ports []string
+		for _, conn := range s.Connections {
+			port := fmt.Sprintf("%s-%d", conn.ControllerID, conn.Channel)
+			if !sliceContains(ports, port) {
+				ports = append(ports, port)
+				portSessions[port]++
+			}
+		}
+	}
+	for _, h := range hosts {
+		for _, i := range h.Initiators {
+			if i.NodeName.IoInterfaceType != "iscsi" {
+				continue
+			}
+			metrics.Initiators = append(metrics.Initiators, IscsiInitiatorMetric{
+				Host:      h.Name,
+				Initiator: i.NodeName.IscsiNodeName,
+				Sessions:  initiatorSessions[i.NodeName.IscsiNodeName],
+			})
+		}
+	}
+	for _, controller := range inventory.Controllers {
+		for _, hi := range controller.HostInterfaces {
+			if hi.Iscsi == nil {
+				continue
+			}
+			port := fmt.Sprintf("%s-%d", controller.ID, hi.Iscsi.Channel)
+			metrics.Ports = append(metrics.Ports, IscsiPortMetric{
+				Controller:      controller.ID,
+				ControllerLabel: controller.PhysicalLocation.Label,
+				Port:            strconv.Itoa(hi.Iscsi.Channel),
+				Sessions:        portSessions[port],
+			})
+		}
+	}
+	return metrics, nil
+}
@@ -0,0 +1,166 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestIscsiCollector(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/iscsi-controllers.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	hostsData, err := os.ReadFile("testdata/hosts.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	sessionsData, err := os.ReadFile("testdata/iscsi-sessions.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	settingsData, err := os.ReadFile("testdata/iscsi-target-settings.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="iscsi",reason="auth"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="connection"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="decode"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="other"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="timeout"} 0
+	# HELP eseries_iscsi_initiator_sessions Number of active iSCSI sessions for host initiator
+	# TYPE eseries_iscsi_initiator_sessions gauge
+	eseries_iscsi_initiator_sessions{host="host1",initiator="iqn.1994-05.com.redhat:host1"} 2
+	eseries_iscsi_initiator_sessions{host="host2",initiator="iqn.1994-05.com.redhat:host2"} 2
+	eseries_iscsi_initiator_sessions{host="host3",initiator="iqn.1994-05.com.redhat:host3"} 0
+	# HELP eseries_iscsi_port_sessions Number of active iSCSI sessions on controller port
+	# TYPE eseries_iscsi_port_sessions gauge
+	eseries_iscsi_port_sessions{controller="070000000000000000000001",controller_label="A",port="1"} 2
+	eseries_iscsi_port_sessions{controller="070000000000000000000001",controller_label="A",port="2"} 1
+	eseries_iscsi_port_sessions{controller="070000000000000000000002",controller_label="B",port="1"} 1
+	eseries_iscsi_port_sessions{controller="070000000000000000000002",controller_label="B",port="2"} 0
+	# HELP eseries_iscsi_target_authentication_method iSCSI target authentication method
+	# TYPE eseries_iscsi_target_authentication_method gauge
+	eseries_iscsi_target_authentication_method{method="chap"} 1
+	eseries_iscsi_target_authentication_method{method="none"} 0
+	eseries_iscsi_target_authentication_method{method="unknown"} 0
+	# HELP eseries_iscsi_target_info iSCSI target information
+	# TYPE eseries_iscsi_target_info gauge
+	eseries_iscsi_target_info{alias="e5660-01",iqn="iqn.1992-01.com.netapp:2806.60080e500043a1b00000000056d6b726"} 1
+	# HELP eseries_iscsi_target_portal_info iSCSI target portal
+	# TYPE eseries_iscsi_target_portal_info gauge
+	eseries_iscsi_target_portal_info{address="10.10.3.11",group_tag="1",port="3260"} 1
+	eseries_iscsi_target_portal_info{address="10.10.3.12",group_tag="1",port="3260"} 1
+	eseries_iscsi_target_portal_info{address="10.10.3.21",group_tag="1",port="3260"} 1
+	eseries_iscsi_target_portal_info{address="10.10.3.22",group_tag="1",port="3260"} 1
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else if strings.HasSuffix(req.URL.Path, "hosts") {
+			_, _ = rw.Write(hostsData)
+		} else if strings.HasSuffix(req.URL.Path, "iscsi/sessions") {
+			_, _ = rw.Write(sessionsData)
+		} else {
+			_, _ = rw.Write(settingsData)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewIscsiExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 23 {
+		t.Errorf("Unexpected collection count %d, expected 23", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_iscsi_initiator_sessions", "eseries_iscsi_port_sessions", "eseries_iscsi_target_info",
+		"eseries_iscsi_target_authentication_method", "eseries_iscsi_target_portal_info",
+		"eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestIscsiCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="iscsi",reason="auth"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="connection"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="decode"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="iscsi",reason="other"} 0
+	eseries_exporter_collect_error{collector="iscsi",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewIscsiExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_iscsi_initiator_sessions", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type NetInterface struct {
+	InterfaceType string                `json:"interfaceType"`
+	Ethernet      *EthernetNetInterface `json:"ethernet"`
+}
+
+type EthernetNetInterface struct {
+	InterfaceName           string                     `json:"interfaceName"`
+	MacAddr                 string                     `json:"macAddr"`
+	LinkStatus              string                     `json:"linkStatus"`
+	Speed                   float64                    `json:"speed"`
+	IPv4Enabled             bool                       `json:"ipv4Enabled"`
+	IPv4Address             string                     `json:"ipv4Address"`
+	IPv4AddressConfigMethod string                     `json:"ipv4AddressConfigMethod"`
+	IPv6Enabled             bool                       `json:"ipv6Enabled"`
+	IPv6AddressConfigMethod string                     `json:"ipv6AddressConfigMethod"`
+	PhysicalLocation        ControllerPhysicalLocation `json:"physicalLocation"`
+}
+
+type NetworkSettings struct {
+	DNSProperties struct {
+		AcquisitionProperties struct {
+			DNSAcquisitionType string                 `json:"dnsAcquisitionType"`
+			DNSServers         []NetworkServerAddress `json:"dnsServers"`
+		} `json:"acquisitionProperties"`
+		DhcpAcquiredDNSServers []NetworkServerAddress `json:"dhcpAcquiredDnsServers"`
+	} `json:"dnsProperties"`
+	NTPProperties struct {
+		AcquisitionProperties struct {
+			NTPAcquisitionType string                 `json:"ntpAcquisitionType"`
+			NTPServers         []NetworkServerAddress `json:"ntpServers"`
+		} `json:"acquisitionProperties"`
+		DhcpAcquiredNTPServers []NetworkServerAddress `json:"dhcpAcquiredNtpServers"`
+	} `json:"ntpProperties"`
+}
+
+type NetworkServerAddress struct {
+	AddressType string `json:"addressType"`
+}
+
+type ManagementInterfacesCollector struct {
+	LinkUp      *prometheus.Desc
+	Speed       *prometheus.Desc
+	Info        *prometheus.Desc
+	IPv4Enabled *prometheus.Desc
+	IPv6Enabled *prometheus.Desc
+	DNSInfo     *prometheus.Desc
+	DNSServers  *prometheus.Desc
+	NTPInfo     *prometheus.Desc
+	NTPServers  *prometheus.Desc
+	ctx         context.Context
+	target      config.Target
+	logger      log.Logger
+}
+
+func init() {
+	registerCollector("management-interfaces", false, NewManagementInterfacesExporter)
+}
+
+func NewManagementInterfacesExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	controllerLabels := []string{"controller", "controller_label"}
+	labels := append(controllerLabels, "interface")
+	return &ManagementInterfacesCollector{
+		LinkUp: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_interface", "link_up"),
+			"Management interface link status, 1=up 0=down", labels, nil),
+		Speed: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_interface", "speed_bytes"),
+			"Management interface link speed in bytes per second", labels, nil),
+		Info: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_interface", "info"),
+			"Management interface information",
+			append(labels, "port", "mac_address", "ipv4_address", "ipv4_config_method", "ipv6_config_method"), nil),
+		IPv4Enabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_interface", "ipv4_enabled"),
+			"Management interface IPv4 enabled, 1=enabled 0=disabled", labels, nil),
+		IPv6Enabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_interface", "ipv6_enabled"),
+			"Management interface IPv6 enabled, 1=enabled 0=disabled", labels, nil),
+		DNSInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_dns", "info"),
+			"Controller DNS acquisition settings", append(controllerLabels, "acquisition_type"), nil),
+		DNSServers: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_dns", "servers"),
+			"Number of DNS servers in use by controller", controllerLabels, nil),
+		NTPInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_ntp", "info"),
+			"Controller NTP acquisition settings", append(controllerLabels, "acquisition_type"), nil),
+		NTPServers: prometheus.NewDesc(prometheus.BuildFQName(namespace, "management_ntp", "servers"),
+			"Number of NTP servers in use by controller", controllerLabels, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *ManagementInterfacesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.LinkUp
+	ch <- c.Speed
+	ch <- c.Info
+	ch <- c.IPv4Enabled
+	ch <- c.IPv6Enabled
+	ch <- c.DNSInfo
+	ch <- c.DNSServers
+	ch <- c.NTPInfo
+	ch <- c.NTPServers
+}
+
+func (c *ManagementInterfacesCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting management-interfaces metrics")
+	collectTime := time.Now()
+	var errorReason string
+	controllers, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	for _, controller := range controllers {
+		label := controller.PhysicalLocation.Label
+		for _, ni := range controller.NetInterfaces {
+			e := ni.Ethernet
+			if e == nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.LinkUp, prometheus.GaugeValue, boolToFloat64(e.LinkStatus == "up"), controller.ID, label, e.InterfaceName)
+			// Speed is reported in Mbit/s
+			ch <- prometheus.MustNewConstMetric(c.Speed, prometheus.GaugeValue, e.Speed*1000*1000/8, controller.ID, label, e.InterfaceName)
+			ch <- prometheus.MustNewConstMetric(c.Info, prometheus.GaugeValue, 1, controller.ID, label, e.InterfaceName,
+				e.PhysicalLocation.Label, e.MacAddr, e.IPv4Address, e.IPv4AddressConfigMethod, e.IPv6AddressConfigMethod)
+			ch <- prometheus.MustNewConstMetric(c.IPv4Enabled, prometheus.GaugeValue, boolToFloat64(e.IPv4Enabled), controller.ID, label, e.InterfaceName)
+			ch <- prometheus.MustNewConstMetric(c.IPv6Enabled, prometheus.GaugeValue, boolToFloat64(e.IPv6Enabled), controller.ID, label, e.InterfaceName)
+		}
+		dns := controller.NetworkSettings.DNSProperties
+		dnsServers := dns.AcquisitionProperties.DNSServers
+		if dns.AcquisitionProperties.DNSAcquisitionType == "dhcp" {
+			dnsServers = dns.DhcpAcquiredDNSServers
+		}
+		ch <- prometheus.MustNewConstMetric(c.DNSInfo, prometheus.GaugeValue, 1, controller.ID, label, dns.AcquisitionProperties.DNSAcquisitionType)
+		ch <- prometheus.MustNewConstMetric(c.DNSServers, prometheus.GaugeValue, float64(len(dnsServers)), controller.ID, label)
+		ntp := controller.NetworkSettings.NTPProperties
+		ntpServers := ntp.AcquisitionProperties.NTPServers
+		if ntp.AcquisitionProperties.NTPAcquisitionType == "dhcp" {
+			ntpServers = ntp.DhcpAcquiredNTPServers
+		}
+		ch <- prometheus.MustNewConstMetric(c.NTPInfo, prometheus.GaugeValue, 1, controller.ID, label, ntp.AcquisitionProperties.NTPAcquisitionType)
+		ch <- prometheus.MustNewConstMetric(c.NTPServers, prometheus.GaugeValue, float64(len(ntpServers)), controller.ID, label)
+	}
+
+	collectErrorMetrics(ch, "management-interfaces", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "management-interfaces")
+}
+
+func (c *ManagementInterfacesCollector) collect() ([]Controller, error) {
+	var inventory ControllersInventory
+	body, err := getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(body, &inventory)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.Controllers, nil
+}
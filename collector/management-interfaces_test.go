@@ -0,0 +1,158 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestManagementInterfacesCollector(t *testing.T) {
+	fixtureData, err := os.ReadFile("testdata/controllers.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="management-interfaces",reason="auth"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="connection"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="decode"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="other"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="timeout"} 0
+	# HELP eseries_management_dns_info Controller DNS acquisition settings
+	# TYPE eseries_management_dns_info gauge
+	eseries_management_dns_info{acquisition_type="dhcp",controller="070000000000000000000001",controller_label="A"} 1
+	eseries_management_dns_info{acquisition_type="dhcp",controller="070000000000000000000002",controller_label="B"} 1
+	# HELP eseries_management_dns_servers Number of DNS servers in use by controller
+	# TYPE eseries_management_dns_servers gauge
+	eseries_management_dns_servers{controller="070000000000000000000001",controller_label="A"} 0
+	eseries_management_dns_servers{controller="070000000000000000000002",controller_label="B"} 0
+	# HELP eseries_management_interface_info Management interface information
+	# TYPE eseries_management_interface_info gauge
+	eseries_management_interface_info{controller="070000000000000000000001",controller_label="A",interface="gei0",ipv4_address="10.10.2.101",ipv4_config_method="configStatic",ipv6_config_method="configStatic",mac_address="0080E543A2C4",port="Port 1"} 1
+	eseries_management_interface_info{controller="070000000000000000000001",controller_label="A",interface="gei1",ipv4_address="0.0.0.0",ipv4_config_method="configDhcp",ipv6_config_method="configStateless",mac_address="0080E543A2C5",port="Port 2"} 1
+	eseries_management_interface_info{controller="070000000000000000000002",controller_label="B",interface="gei0",ipv4_address="10.10.2.102",ipv4_config_method="configStatic",ipv6_config_method="configStatic",mac_address="0080E543A1B0",port="Port 1"} 1
+	eseries_management_interface_info{controller="070000000000000000000002",controller_label="B",interface="gei1",ipv4_address="0.0.0.0",ipv4_config_method="configDhcp",ipv6_config_method="configStateless",mac_address="0080E543A1B1",port="Port 2"} 1
+	# HELP eseries_management_interface_ipv6_enabled Management interface IPv6 enabled, 1=enabled 0=disabled
+	# TYPE eseries_management_interface_ipv6_enabled gauge
+	eseries_management_interface_ipv6_enabled{controller="070000000000000000000001",controller_label="A",interface="gei0"} 0
+	eseries_management_interface_ipv6_enabled{controller="070000000000000000000001",controller_label="A",interface="gei1"} 1
+	eseries_management_interface_ipv6_enabled{controller="070000000000000000000002",controller_label="B",interface="gei0"} 0
+	eseries_management_interface_ipv6_enabled{controller="070000000000000000000002",controller_label="B",interface="gei1"} 1
+	# HELP eseries_management_interface_link_up Management interface link status, 1=up 0=down
+	# TYPE eseries_management_interface_link_up gauge
+	eseries_management_interface_link_up{controller="070000000000000000000001",controller_label="A",interface="gei0"} 1
+	eseries_management_interface_link_up{controller="070000000000000000000001",controller_label="A",interface="gei1"} 0
+	eseries_management_interface_link_up{controller="070000000000000000000002",controller_label="B",interface="gei0"} 1
+	eseries_management_interface_link_up{controller="070000000000000000000002",controller_label="B",interface="gei1"} 0
+	# HELP eseries_management_interface_speed_bytes Management interface link speed in bytes per second
+	# TYPE eseries_management_interface_speed_bytes gauge
+	eseries_management_interface_speed_bytes{controller="070000000000000000000001",controller_label="A",interface="gei0"} 1.25e+08
+	eseries_management_interface_speed_bytes{controller="070000000000000000000001",controller_label="A",interface="gei1"} 0
+	eseries_management_interface_speed_bytes{controller="070000000000000000000002",controller_label="B",interface="gei0"} 1.25e+08
+	eseries_management_interface_speed_bytes{controller="070000000000000000000002",controller_label="B",interface="gei1"} 0
+	# HELP eseries_management_ntp_info Controller NTP acquisition settings
+	# TYPE eseries_management_ntp_info gauge
+	eseries_management_ntp_info{acquisition_type="disabled",controller="070000000000000000000001",controller_label="A"} 1
+	eseries_management_ntp_info{acquisition_type="disabled",controller="070000000000000000000002",controller_label="B"} 1
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(fixtureData)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewManagementInterfacesExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 36 {
+		t.Errorf("Unexpected collection count %d, expected 36", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_management_interface_link_up", "eseries_management_interface_speed_bytes",
+		"eseries_management_interface_info", "eseries_management_interface_ipv6_enabled",
+		"eseries_management_dns_info", "eseries_management_dns_servers", "eseries_management_ntp_info",
+		"eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestManagementInterfacesCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="management-interfaces",reason="auth"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="connection"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="decode"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="management-interfaces",reason="other"} 0
+	eseries_exporter_collect_error{collector="management-interfaces",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewManagementInterfacesExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_management_interface_link_up", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type MediaScanProgress struct {
+	VolumeRef          string  `json:"volumeRef"`
+	PercentComplete    float64 `json:"percentComplete"`
+	LastCompletionTime string  `json:"lastCompletionTime"`
+}
+
+type VolumeMediaScanMetric struct {
+	Volume                 string
+	Enabled                bool
+	RedundancyCheckEnabled bool
+	Progress               *MediaScanProgress
+}
+
+type MediaScanMetrics struct {
+	Period  float64
+	Volumes []VolumeMediaScanMetric
+}
+
+type MediaScanCollector struct {
+	Period                 *prometheus.Desc
+	Enabled                *prometheus.Desc
+	RedundancyCheckEnabled *prometheus.Desc
+	Progress               *prometheus.Desc
+	LastCompleted          *prometheus.Desc
+	ctx                    context.Context
+	target                 config.Target
+	logger                 log.Logger
+}
+
+func init() {
+	registerCollector("media-scan", false, NewMediaScanExporter)
+}
+
+func NewMediaScanExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	labels := []string{"volume"}
+	return &MediaScanCollector{
+		Period: prometheus.NewDesc(prometheus.BuildFQName(namespace, "media_scan", "duration_seconds"),
+			"Configured duration of a full media scan of the storage system", nil, nil),
+		Enabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "volume", "media_scan_enabled"),
+			"Media scan enabled for volume, 1=enabled 0=disabled", labels, nil),
+		RedundancyCheckEnabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "volume", "redundancy_check_enabled"),
+			"Media scan redundancy check enabled for volume, 1=enabled 0=disabled", labels, nil),
+		Progress: prometheus.NewDesc(prometheus.BuildFQName(namespace, "volume", "media_scan_progress_ratio"),
+			"Progress of the current media scan of volume", labels, nil),
+		LastCompleted: prometheus.NewDesc(prometheus.BuildFQName(namespace, "volume", "media_scan_last_completed_timestamp_seconds"),
+			"Timestamp of the last completed media scan of volume", labels, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *MediaScanCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Period
+	ch <- c.Enabled
+	ch <- c.RedundancyCheckEnabled
+	ch <- c.Progress
+	ch <- c.LastCompleted
+}
+
+func (c *MediaScanCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting media-scan metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	if err == nil {
+		ch <- prometheus.MustNewConstMetric(c.Period, prometheus.GaugeValue, metrics.Period*24*60*60)
+		for _, v := range metrics.Volumes {
+			ch <- prometheus.MustNewConstMetric(c.Enabled, prometheus.GaugeValue, boolToFloat64(v.Enabled), v.Volume)
+			ch <- prometheus.MustNewConstMetric(c.RedundancyCheckEnabled, prometheus.GaugeValue, boolToFloat64(v.RedundancyCheckEnabled), v.Volume)
+			if v.Progress == nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.Progress, prometheus.GaugeValue, v.Progress.PercentComplete/100, v.Volume)
+			if lastCompleted, err := parseTimestamp(v.Progress.LastCompletionTime); err == nil {
+				ch <- prometheus.MustNewConstMetric(c.LastCompleted, prometheus.GaugeValue, float64(lastCompleted.Unix()), v.Volume)
+			} else if v.Progress.LastCompletionTime != "" {
+				level.Error(c.logger).Log("msg", "Unable to parse lastCompletionTime", "volume", v.Volume, "lastCompletionTime", v.Progress.LastCompletionTime, "err", err)
+				errorReason = "decode"
+			}
+		}
+	}
+
+	collectErrorMetrics(ch, "media-scan", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "media-scan")
+}
+
+func (c *MediaScanCollector) collect() (MediaScanMetrics, error) {
+	var metrics MediaScanMetrics
+	var system StorageSystem
+	var volumes []Volume
+	var progress []MediaScanProgress
+	var systemBody, volumesBody, progressBody []byte
+	var systemErr, volumesErr, progressErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		systemBody, systemErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		volumesBody, volumesErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/volumes", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		progressBody, progressErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/media-scan-progress", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if systemErr != nil {
+		return metrics, systemErr
+	}
+	if volumesErr != nil {
+		return metrics, volumesErr
+	}
+	if progressErr != nil {
+		return metrics, progressErr
+	}
+	err := json.Unmarshal(systemBody, &system)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(volumesBody, &volumes)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(progressBody, &progress)
+	if err != nil {
+		return metrics, err
+	}
+
+	metrics.Period = system.MediaScanPeriod
+	volumeProgress := make(map[string]*MediaScanProgress)
+	for i := range progress {
+		volumeProgress[progress[i].VolumeRef] = &progress[i]
+	}
+	for _, v := range volumes {
+		metrics.Volumes = append(metrics.Volumes, VolumeMediaScanMetric{
+			Volume:                 v.Name,
+			Enabled:                v.MediaScan.Enable,
+			RedundancyCheckEnabled: v.MediaScan.ParityValidationEnable,
+			Progress:               volumeProgress[v.ID],
+		})
+	}
+	return metrics, nil
+}
@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestMediaScanCollector(t *testing.T) {
+	systemData, err := os.ReadFile("testdata/storage-systems.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	volumesData, err := os.ReadFile("testdata/volumes.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	progressData, err := os.ReadFile("testdata/media-scan-progress.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="media-scan",reason="auth"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="connection"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="decode"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="other"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="timeout"} 0
+	# HELP eseries_media_scan_duration_seconds Configured duration of a full media scan of the storage system
+	# TYPE eseries_media_scan_duration_seconds gauge
+	eseries_media_scan_duration_seconds 2.592e+06
+	# HELP eseries_volume_media_scan_enabled Media scan enabled for volume, 1=enabled 0=disabled
+	# TYPE eseries_volume_media_scan_enabled gauge
+	eseries_volume_media_scan_enabled{volume="apps"} 1
+	eseries_volume_media_scan_enabled{volume="home"} 1
+	eseries_volume_media_scan_enabled{volume="project"} 0
+	eseries_volume_media_scan_enabled{volume="scratch"} 1
+	# HELP eseries_volume_media_scan_last_completed_timestamp_seconds Timestamp of the last completed media scan of volume
+	# TYPE eseries_volume_media_scan_last_completed_timestamp_seconds gauge
+	eseries_volume_media_scan_last_completed_timestamp_seconds{volume="apps"} 1.7605e+09
+	eseries_volume_media_scan_last_completed_timestamp_seconds{volume="home"} 1.76e+09
+	# HELP eseries_volume_media_scan_progress_ratio Progress of the current media scan of volume
+	# TYPE eseries_volume_media_scan_progress_ratio gauge
+	eseries_volume_media_scan_progress_ratio{volume="apps"} 1
+	eseries_volume_media_scan_progress_ratio{volume="home"} 0.45
+	eseries_volume_media_scan_progress_ratio{volume="scratch"} 0
+	# HELP eseries_volume_redundancy_check_enabled Media scan redundancy check enabled for volume, 1=enabled 0=disabled
+	# TYPE eseries_volume_redundancy_check_enabled gauge
+	eseries_volume_redundancy_check_enabled{volume="apps"} 0
+	eseries_volume_redundancy_check_enabled{volume="home"} 1
+	eseries_volume_redundancy_check_enabled{volume="project"} 0
+	eseries_volume_redundancy_check_enabled{volume="scratch"} 1
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "volumes") {
+			_, _ = rw.Write(volumesData)
+		} else if strings.HasSuffix(req.URL.Path, "media-scan-progress") {
+			_, _ = rw.Write(progressData)
+		} else {
+			_, _ = rw.Write(systemData)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewMediaScanExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 22 {
+		t.Errorf("Unexpected collection count %d, expected 22", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_media_scan_duration_seconds", "eseries_volume_media_scan_enabled",
+		"eseries_volume_redundancy_check_enabled", "eseries_volume_media_scan_progress_ratio",
+		"eseries_volume_media_scan_last_completed_timestamp_seconds", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestMediaScanCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="media-scan",reason="auth"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="connection"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="decode"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="media-scan",reason="other"} 0
+	eseries_exporter_collect_error{collector="media-scan",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewMediaScanExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_volume_media_scan_enabled", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type NvmeofInterface struct {
+	ID            string `json:"id"`
+	ControllerID  string `json:"controllerId"`
+	Channel       int    `json:"channel"`
+	InterfaceData struct {
+		Type         string `json:"type"`
+		EthernetData *struct {
+			LinkStatus string `json:"linkStatus"`
+		} `json:"ethernetData"`
+		IbData *struct {
+			LinkState string `json:"linkState"`
+		} `json:"ibData"`
+		FibreData *struct {
+			LinkStatus string `json:"linkStatus"`
+		} `json:"fibreData"`
+	} `json:"interfaceData"`
+}
+
+type NvmeofConnection struct {
+	HostNqn      string `json:"hostNqn"`
+	ControllerID string `json:"controllerId"`
+	Channel      int    `json:"channel"`
+}
+
+type VolumeMapping struct {
+	VolumeRef string `json:"volumeRef"`
+	MapRef    string `json:"mapRef"`
+	Type      string `json:"type"`
+}
+
+type NvmeofPortMetric struct {
+	Controller      string
+	ControllerLabel string
+	Port            string
+	Transport       string
+	LinkUp          bool
+	ConnectedHosts  float64
+}
+
+type NvmeofHostMetric struct {
+	Host       string
+	Namespaces float64
+}
+
+type NvmeofMetrics struct {
+	Ports []NvmeofPortMetric
+	Hosts []NvmeofHostMetric
+}
+
+type NvmeofCollector struct {
+	PortLinkUp         *prometheus.Desc
+	PortConnectedHosts *prometheus.Desc
+	HostNamespaces     *prometheus.Desc
+	ctx                context.Context
+	target             config.Target
+	logger             log.Logger
+}
+
+func init() {
+	registerCollector("nvmeof", false, NewNvmeofExporter)
+}
+
+func NewNvmeofExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	return &NvmeofCollector{
+		PortLinkUp: prometheus.NewDesc(prometheus.BuildFQName(namespace, "nvmeof_port", "link_up"),
+			"Link state of NVMe-oF controller port, 1=up 0=down", []string{"controller", "controller_label", "port", "transport"}, nil),
+		PortConnectedHosts: prometheus.NewDesc(prometheus.BuildFQName(namespace, "nvmeof_port", "connected_hosts"),
+			"Number of hosts connected to NVMe-oF controller port", []string{"controller", "controller_label", "port"}, nil),
+		HostNamespaces: prometheus.NewDesc(prometheus.BuildFQName(namespace, "nvmeof_host", "namespaces"),
+			"Number of namespaces mapped to NVMe-oF host", []string{"host"}, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *NvmeofCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.PortLinkUp
+	ch <- c.PortConnectedHosts
+	ch <- c.HostNamespaces
+}
+
+func (c *NvmeofCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting nvmeof metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	for _, p := range metrics.Ports {
+		ch <- prometheus.MustNewConstMetric(c.PortLinkUp, prometheus.GaugeValue, boolToFloat64(p.LinkUp), p.Controller, p.ControllerLabel, p.Port, p.Transport)
+		ch <- prometheus.MustNewConstMetric(c.PortConnectedHosts, prometheus.GaugeValue, p.ConnectedHosts, p.Controller, p.ControllerLabel, p.Port)
+	}
+	for _, h := range metrics.Hosts {
+		ch <- prometheus.MustNewConstMetric(c.HostNamespaces, prometheus.GaugeValue, h.Namespaces, h.Host)
+	}
+
+	collectErrorMetrics(ch, "nvmeof", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "nvmeof")
+}
+
+func (c *NvmeofCollector) collect() (NvmeofMetrics, error) {
+	var metrics NvmeofMetrics
+	var inventory ControllersInventory
+	var hosts []Host
+	var connections []NvmeofConnection
+	var mappings []VolumeMapping
+	var inventoryBody, hostsBody, connectionsBody, mappingsBody []byte
+	var inventoryErr, hostsErr, connectionsErr, mappingsErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		hostsBody, hostsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hosts", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		connectionsBody, connectionsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/nvmeof/connections", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		mappingsBody, mappingsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/volume-mappings", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if inventoryErr != nil {
+		return metrics, inventoryErr
+	}
+	if hostsErr != nil {
+		return metrics, hostsErr
+	}
+	if connectionsErr != nil {
+		return metrics, connectionsErr
+	}
+	if mappingsErr != nil {
+		return metrics, mappingsErr
+	}
+	err := json.Unmarshal(inventoryBody, &inventory)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(hostsBody, &hosts)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(connectionsBody, &connections)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(mappingsBody, &mappings)
+	if err != nil {
+		return metrics, err
+	}
+
+	portHosts := make(map[string][]string)
+	for _, conn := range connections {
+		port := fmt.Sprintf("%s-%d", conn.ControllerID, conn.Channel)
+		if !sliceContains(portHosts[port], conn.HostNqn) {
+			portHosts[port] = append(portHosts[port], conn.HostNqn)
+		}
+	}
+	for _, controller := range inventory.Controllers {
+		for _, hi := range controller.HostInterfaces {
+			if hi.Nvmeof == nil {
+				continue
+			}
+			port := fmt.Sprintf("%s-%d", controller.ID, hi.Nvmeof.Channel)
+			metrics.Ports = append(metrics.Ports, NvmeofPortMetric{
+				Controller:      controller.ID,
+				ControllerLabel: controller.PhysicalLocation.Label,
+				Port:            strconv.Itoa(hi.Nvmeof.Channel),
+				Transport:       hi.Nvmeof.InterfaceData.Type,
+				LinkUp:          nvmeofLinkUp(hi.Nvmeof),
+				ConnectedHosts:  float64(len(portHosts[port])),
+			})
+		}
+	}
+	for _, h := range hosts {
+		var isNvmeof bool
+		for _, i := range h.Initiators {
+			if i.NodeName.IoInterfaceType == "nvmeof" {
+				isNvmeof = true
+			}
+		}
+		if !isNvmeof {
+			level.Debug(c.logger).Log("msg", "Skipping host without NVMe-oF initiators", "host", h.Name)
+			continue
+		}
+		var namespaces float64
+		for _, m := range mappings {
+			if m.MapRef == h.ID || (m.Type == "cluster" && m.MapRef == h.ClusterRef) {
+				namespaces++
+			}
+		}
+		metrics.Hosts = append(metrics.Hosts, NvmeofHostMetric{
+			Host:       h.Name,
+			Namespaces: namespaces,
+		})
+	}
+	return metrics, nil
+}
+
+func nvmeofLinkUp(i *NvmeofInterface) bool {
+	data := i.InterfaceData
+	switch {
+	case data.EthernetData != nil:
+		return data.EthernetData.LinkStatus == "up"
+	case data.IbData != nil:
+		return data.IbData.LinkState == "active"
+	case data.FibreData != nil:
+		return data.FibreData.LinkStatus == "up"
+	}
+	return false
+}
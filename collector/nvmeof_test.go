@@ -0,0 +1,157 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestNvmeofCollector(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/nvme-hardware-inventory.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	hostsData, err := os.ReadFile("testdata/nvme-hosts.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	connectionsData, err := os.ReadFile("testdata/nvmeof-connections.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	mappingsData, err := os.ReadFile("testdata/volume-mappings.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="nvmeof",reason="auth"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="connection"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="decode"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="other"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="timeout"} 0
+	# HELP eseries_nvmeof_host_namespaces Number of namespaces mapped to NVMe-oF host
+	# TYPE eseries_nvmeof_host_namespaces gauge
+	eseries_nvmeof_host_namespaces{host="ef1"} 2
+	eseries_nvmeof_host_namespaces{host="ef2"} 1
+	eseries_nvmeof_host_namespaces{host="ef3"} 0
+	# HELP eseries_nvmeof_port_connected_hosts Number of hosts connected to NVMe-oF controller port
+	# TYPE eseries_nvmeof_port_connected_hosts gauge
+	eseries_nvmeof_port_connected_hosts{controller="070000000000000000000001",controller_label="A",port="1"} 2
+	eseries_nvmeof_port_connected_hosts{controller="070000000000000000000001",controller_label="A",port="2"} 1
+	eseries_nvmeof_port_connected_hosts{controller="070000000000000000000002",controller_label="B",port="1"} 1
+	eseries_nvmeof_port_connected_hosts{controller="070000000000000000000002",controller_label="B",port="2"} 0
+	# HELP eseries_nvmeof_port_link_up Link state of NVMe-oF controller port, 1=up 0=down
+	# TYPE eseries_nvmeof_port_link_up gauge
+	eseries_nvmeof_port_link_up{controller="070000000000000000000001",controller_label="A",port="1",transport="roce"} 1
+	eseries_nvmeof_port_link_up{controller="070000000000000000000001",controller_label="A",port="2",transport="roce"} 1
+	eseries_nvmeof_port_link_up{controller="070000000000000000000002",controller_label="B",port="1",transport="roce"} 1
+	eseries_nvmeof_port_link_up{controller="070000000000000000000002",controller_label="B",port="2",transport="roce"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else if strings.HasSuffix(req.URL.Path, "hosts") {
+			_, _ = rw.Write(hostsData)
+		} else if strings.HasSuffix(req.URL.Path, "connections") {
+			_, _ = rw.Write(connectionsData)
+		} else {
+			_, _ = rw.Write(mappingsData)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewNvmeofExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 19 {
+		t.Errorf("Unexpected collection count %d, expected 19", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_nvmeof_port_link_up", "eseries_nvmeof_port_connected_hosts",
+		"eseries_nvmeof_host_namespaces", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestNvmeofCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="nvmeof",reason="auth"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="connection"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="decode"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="nvmeof",reason="other"} 0
+	eseries_exporter_collect_error{collector="nvmeof",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewNvmeofExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_nvmeof_port_link_up", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+var (
+	lastCollection = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "exporter", "last_collection_timestamp_seconds"),
+		"Timestamp of the last background collection",
+		[]string{"collector"}, nil)
+)
+
+// Poller collects the poll_targets of each module in the background and
+// keeps the last snapshot of each collector to serve on scrape
+type Poller struct {
+	sync.RWMutex
+	snapshots map[string]map[string]*pollSnapshot
+	jobs      map[string]map[string]*pollJob
+	wg        sync.WaitGroup
+	logger    log.Logger
+}
+
+type pollSnapshot struct {
+	metrics   []prometheus.Metric
+	timestamp time.Time
+	maxAge    time.Duration
+}
+
+// pollJob is the background polling of one collector of a target, the job
+// is only restarted by a reload when its settings change
+type pollJob struct {
+	cancel   context.CancelFunc
+	settings pollSettings
+	target   config.Target
+}
+
+type pollSettings struct {
+	interval     time.Duration
+	maxAge       time.Duration
+	user         string
+	password     string
+	proxyURL     string
+	retries      int
+	retryBackoff time.Duration
+}
+
+type pollerCollector struct {
+	poller *Poller
+	key    string
+}
+
+func NewPoller(logger log.Logger) *Poller {
+	return &Poller{
+		snapshots: make(map[string]map[string]*pollSnapshot),
+		jobs:      make(map[string]map[string]*pollJob),
+		logger:    logger,
+	}
+}
+
+func pollKey(module string, target string) string {
+	return module + "/" + target
+}
+
+// Update starts polling the targets in the config, polls whose settings are
+// unchanged keep running with their snapshots and only pick up the new target
+func (p *Poller) Update(c *config.Config) {
+	p.Lock()
+	defer p.Unlock()
+	seen := make(map[string]map[string]bool)
+	for moduleName, module := range c.Modules {
+		baseURL, err := url.Parse(module.ProxyURL)
+		if err != nil {
+			level.Error(p.logger).Log("msg", "Unable to parse ProxyURL", "module", moduleName, "err", err)
+			continue
+		}
+		for _, t := range module.PollTargets {
+			target := config.Target{
+				Name:         t,
+				Module:       moduleName,
+				User:         module.User,
+				Password:     module.Password,
+				ProxyURL:     module.ProxyURL,
+				Collectors:   module.Collectors,
+				BaseURL:      baseURL,
+				HttpClient:   module.HttpClient,
+				Limiter:      module.Limiter,
+				Retries:      module.Retries,
+				RetryBackoff: time.Duration(module.RetryBackoff * float64(time.Second)),
+			}
+			key := pollKey(moduleName, t)
+			if _, ok := p.snapshots[key]; !ok {
+				p.snapshots[key] = make(map[string]*pollSnapshot)
+			}
+			if _, ok := p.jobs[key]; !ok {
+				p.jobs[key] = make(map[string]*pollJob)
+			}
+			seen[key] = make(map[string]bool)
+			for _, name := range enabledCollectors(module.Collectors) {
+				seen[key][name] = true
+				interval := module.PollInterval
+				if i, ok := module.PollIntervals[name]; ok && i > 0 {
+					interval = i
+				}
+				maxAge := time.Duration(module.PollMaxAge) * time.Second
+				if maxAge == 0 {
+					maxAge = 3 * time.Duration(interval) * time.Second
+				}
+				settings := pollSettings{
+					interval:     time.Duration(interval) * time.Second,
+					maxAge:       maxAge,
+					user:         target.User,
+					password:     target.Password,
+					proxyURL:     target.ProxyURL,
+					retries:      target.Retries,
+					retryBackoff: target.RetryBackoff,
+				}
+				logger := log.With(p.logger, "collector", name, "target", t, "module", moduleName)
+				if job, ok := p.jobs[key][name]; ok {
+					if job.settings == settings {
+						job.target = target
+						continue
+					}
+					level.Debug(logger).Log("msg", "Restarting background polling after settings changed")
+					job.cancel()
+					delete(p.snapshots[key], name)
+				}
+				level.Debug(logger).Log("msg", "Starting background polling", "interval", interval)
+				ctx, cancel := context.WithCancel(context.Background())
+				job := &pollJob{cancel: cancel, settings: settings, target: target}
+				p.jobs[key][name] = job
+				p.wg.Add(1)
+				go p.poll(ctx, key, name, job, logger)
+			}
+		}
+	}
+	for key, jobs := range p.jobs {
+		for name, job := range jobs {
+			if !seen[key][name] {
+				job.cancel()
+				delete(jobs, name)
+				delete(p.snapshots[key], name)
+			}
+		}
+		if len(jobs) == 0 {
+			delete(p.jobs, key)
+			delete(p.snapshots, key)
+		}
+	}
+}
+
+// Stop cancels running polls and waits for them to return
+func (p *Poller) Stop() {
+	p.Lock()
+	for _, jobs := range p.jobs {
+		for _, job := range jobs {
+			job.cancel()
+		}
+	}
+	p.jobs = make(map[string]map[string]*pollJob)
+	p.Unlock()
+	p.wg.Wait()
+}
+
+// Polled returns true if the target of the module is collected in the background
+func (p *Poller) Polled(module string, target string) bool {
+	p.RLock()
+	defer p.RUnlock()
+	_, ok := p.snapshots[pollKey(module, target)]
+	return ok
+}
+
+// Collector returns a collector that serves the snapshots of the target
+func (p *Poller) Collector(module string, target string) Collector {
+	return &pollerCollector{poller: p, key: pollKey(module, target)}
+}
+
+func (p *Poller) poll(ctx context.Context, key string, name string, job *pollJob, logger log.Logger) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(job.settings.interval)
+	defer ticker.Stop()
+	for {
+		p.collect(ctx, key, name, job, logger)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) collect(ctx context.Context, key string, name string, job *pollJob, logger log.Logger) {
+	p.RLock()
+	target := job.target
+	p.RUnlock()
+	target.RequestCache = newRequestCache()
+	collector := factories[name](ctx, target, logger)
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+	var metrics []prometheus.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	p.Lock()
+	defer p.Unlock()
+	// A job replaced or removed by a reload must not overwrite the snapshots
+	if ctx.Err() != nil || p.jobs[key][name] != job {
+		return
+	}
+	p.snapshots[key][name] = &pollSnapshot{metrics: metrics, timestamp: time.Now(), maxAge: job.settings.maxAge}
+}
+
+func (c *pollerCollector) Describe(ch chan<- *prometheus.Desc) {
+}
+
+func (c *pollerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.poller.RLock()
+	defer c.poller.RUnlock()
+	for name, s := range c.poller.snapshots[c.key] {
+		ch <- prometheus.MustNewConstMetric(lastCollection, prometheus.GaugeValue, float64(s.timestamp.Unix()), name)
+		if time.Since(s.timestamp) > s.maxAge {
+			level.Debug(c.poller.logger).Log("msg", "Skipping stale snapshot", "collector", name, "target", c.key, "age", time.Since(s.timestamp))
+			continue
+		}
+		for _, m := range s.metrics {
+			ch <- m
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestPoller(t *testing.T) {
+	fixtureData, err := os.ReadFile("testdata/drives.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(fixtureData)
+	}))
+	defer server.Close()
+	c := &config.Config{Modules: map[string]*config.Module{
+		"default": {
+			User:         "test",
+			Password:     "test",
+			ProxyURL:     server.URL,
+			Collectors:   []string{"drives"},
+			PollTargets:  []string{"test"},
+			PollInterval: 3600,
+			HttpClient:   &http.Client{},
+		},
+	}}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	poller := NewPoller(logger)
+	poller.Update(c)
+	defer poller.Stop()
+
+	if !poller.Polled("default", "test") {
+		t.Errorf("Expected target test to be polled")
+	}
+	if poller.Polled("default", "other") {
+		t.Errorf("Unexpected target other to be polled")
+	}
+	for i := 0; i < 50; i++ {
+		poller.RLock()
+		_, ok := poller.snapshots["default/test"]["drives"]
+		poller.RUnlock()
+		if ok {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	gatherer := setupGatherer(poller.Collector("default", "test"))
+	if val, err := testutil.GatherAndCount(gatherer); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 43 {
+		t.Errorf("Unexpected collection count %d, expected 43", val)
+	}
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="drives",reason="auth"} 0
+	eseries_exporter_collect_error{collector="drives",reason="connection"} 0
+	eseries_exporter_collect_error{collector="drives",reason="decode"} 0
+	eseries_exporter_collect_error{collector="drives",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="drives",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="drives",reason="other"} 0
+	eseries_exporter_collect_error{collector="drives",reason="timeout"} 0
+	`
+	if err := testutil.GatherAndCompare(gatherer, strings.NewReader(expected), "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	poller.Lock()
+	poller.snapshots["default/test"]["drives"].timestamp = time.Now().Add(-4 * time.Hour)
+	poller.Unlock()
+	if val, err := testutil.GatherAndCount(gatherer, "eseries_exporter_last_collection_timestamp_seconds", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 1 {
+		t.Errorf("Unexpected collection count %d for stale snapshot, expected 1", val)
+	}
+}
+
+func TestPollerUpdate(t *testing.T) {
+	fixtureData, err := os.ReadFile("testdata/drives.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(fixtureData)
+	}))
+	defer server.Close()
+	module := func(interval int) *config.Config {
+		return &config.Config{Modules: map[string]*config.Module{
+			"default": {
+				User:         "test",
+				Password:     "test",
+				ProxyURL:     server.URL,
+				Collectors:   []string{"drives"},
+				PollTargets:  []string{"test"},
+				PollInterval: interval,
+				HttpClient:   &http.Client{},
+			},
+		}}
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	poller := NewPoller(logger)
+	poller.Update(module(3600))
+	defer poller.Stop()
+
+	var snapshot *pollSnapshot
+	for i := 0; i < 50; i++ {
+		poller.RLock()
+		snapshot = poller.snapshots["default/test"]["drives"]
+		poller.RUnlock()
+		if snapshot != nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if snapshot == nil {
+		t.Fatalf("Expected snapshot of drives")
+	}
+	poller.RLock()
+	job := poller.jobs["default/test"]["drives"]
+	poller.RUnlock()
+
+	c := module(3600)
+	poller.Update(c)
+	poller.RLock()
+	if poller.snapshots["default/test"]["drives"] != snapshot {
+		t.Errorf("Expected snapshot to be kept when settings are unchanged")
+	}
+	if poller.jobs["default/test"]["drives"] != job {
+		t.Errorf("Expected poll to keep running when settings are unchanged")
+	}
+	if job.target.HttpClient != c.Modules["default"].HttpClient {
+		t.Errorf("Expected poll to use the HttpClient of the new config")
+	}
+	poller.RUnlock()
+
+	poller.Update(module(1800))
+	poller.RLock()
+	if poller.jobs["default/test"]["drives"] == job {
+		t.Errorf("Expected poll to restart when interval changes")
+	}
+	poller.RUnlock()
+
+	c = module(1800)
+	c.Modules["default"].PollTargets = []string{"other"}
+	poller.Update(c)
+	if poller.Polled("default", "test") {
+		t.Errorf("Unexpected target test to be polled after removal")
+	}
+	if !poller.Polled("default", "other") {
+		t.Errorf("Expected target other to be polled")
+	}
+}
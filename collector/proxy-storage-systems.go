@@ -0,0 +1,124 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type ProxyStorageSystemsCollector struct {
+	Status              *prometheus.Desc
+	LastContactedAge    *prometheus.Desc
+	ControllerReachable *prometheus.Desc
+	FirmwareInfo        *prometheus.Desc
+	ctx                 context.Context
+	target              config.Target
+	logger              log.Logger
+}
+
+func init() {
+	registerProxyCollector("proxy-storage-systems", NewProxyStorageSystemsExporter)
+}
+
+func NewProxyStorageSystemsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	labels := []string{"system", "name"}
+	return &ProxyStorageSystemsCollector{
+		Status: prometheus.NewDesc(prometheus.BuildFQName(namespace, "proxy_storage_system", "status"),
+			"Status of storage system registered in the proxy, 1=current status 0=all other states",
+			append(labels, "status"), nil),
+		LastContactedAge: prometheus.NewDesc(prometheus.BuildFQName(namespace, "proxy_storage_system", "last_contacted_age_seconds"),
+			"Seconds since the proxy last contacted the storage system", labels, nil),
+		ControllerReachable: prometheus.NewDesc(prometheus.BuildFQName(namespace, "proxy_storage_system", "controller_reachable"),
+			"Controller address is an active management path of the proxy, 1=reachable 0=unreachable",
+			append(labels, "controller", "address"), nil),
+		FirmwareInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, "proxy_storage_system", "firmware_info"),
+			"Firmware versions of storage system registered in the proxy",
+			append(labels, "firmware_version", "app_version", "boot_version", "nvsram_version"), nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *ProxyStorageSystemsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Status
+	ch <- c.LastContactedAge
+	ch <- c.ControllerReachable
+	ch <- c.FirmwareInfo
+}
+
+func (c *ProxyStorageSystemsCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting proxy-storage-systems metrics")
+	collectTime := time.Now()
+	var errorReason string
+	systems, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	for _, s := range systems {
+		for _, status := range storageSystemsStatuses {
+			var value float64
+			if status == s.Status {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.Status, prometheus.GaugeValue, value, s.ID, s.Name, status)
+		}
+		var unknown float64
+		if !sliceContains(storageSystemsStatuses, s.Status) {
+			unknown = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.Status, prometheus.GaugeValue, unknown, s.ID, s.Name, "unknown")
+		if lastContacted, err := parseTimestamp(s.LastContacted); err == nil {
+			ch <- prometheus.MustNewConstMetric(c.LastContactedAge, prometheus.GaugeValue, collectTime.Sub(lastContacted).Seconds(), s.ID, s.Name)
+		} else if s.LastContacted != "" {
+			level.Error(c.logger).Log("msg", "Unable to parse lastContacted", "system", s.ID, "lastContacted", s.LastContacted, "err", err)
+			errorReason = "decode"
+		}
+		for _, controller := range s.Controllers {
+			for _, address := range controller.IPAddresses {
+				var reachable float64
+				if sliceContains(s.ManagementPaths, address) {
+					reachable = 1
+				}
+				ch <- prometheus.MustNewConstMetric(c.ControllerReachable, prometheus.GaugeValue, reachable, s.ID, s.Name, controller.ControllerID, address)
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(c.FirmwareInfo, prometheus.GaugeValue, 1, s.ID, s.Name, s.FwVersion, s.AppVersion, s.BootVersion, s.NvsramVersion)
+	}
+
+	collectErrorMetrics(ch, "proxy-storage-systems", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "proxy-storage-systems")
+}
+
+func (c *ProxyStorageSystemsCollector) collect() ([]StorageSystem, error) {
+	var systems []StorageSystem
+	body, err := getRequest(c.ctx, c.target, "/devmgr/v2/storage-systems", c.logger)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(body, &systems)
+	if err != nil {
+		return nil, err
+	}
+	return systems, nil
+}
@@ -0,0 +1,162 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestProxyStorageSystemsCollector(t *testing.T) {
+	fixtureData, err := os.ReadFile("testdata/proxy-storage-systems.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="auth"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="connection"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="decode"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="other"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="timeout"} 0
+	# HELP eseries_proxy_storage_system_controller_reachable Controller address is an active management path of the proxy, 1=reachable 0=unreachable
+	# TYPE eseries_proxy_storage_system_controller_reachable gauge
+	eseries_proxy_storage_system_controller_reachable{address="10.10.2.101",controller="070000000000000000000001",name="e5660-01",system="e5660-01"} 1
+	eseries_proxy_storage_system_controller_reachable{address="10.10.2.102",controller="070000000000000000000002",name="e5660-01",system="e5660-01"} 1
+	eseries_proxy_storage_system_controller_reachable{address="10.10.2.103",controller="070000000000000000000001",name="e5660-02",system="e5660-02"} 0
+	eseries_proxy_storage_system_controller_reachable{address="10.10.2.104",controller="070000000000000000000002",name="e5660-02",system="e5660-02"} 1
+	eseries_proxy_storage_system_controller_reachable{address="10.10.2.105",controller="",name="",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	eseries_proxy_storage_system_controller_reachable{address="10.10.2.106",controller="",name="",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	# HELP eseries_proxy_storage_system_firmware_info Firmware versions of storage system registered in the proxy
+	# TYPE eseries_proxy_storage_system_firmware_info gauge
+	eseries_proxy_storage_system_firmware_info{app_version="08.40.50.00",boot_version="08.40.50.00",firmware_version="08.40.50.00",name="e5660-01",nvsram_version="N5600-840834-D03",system="e5660-01"} 1
+	eseries_proxy_storage_system_firmware_info{app_version="08.40.60.01",boot_version="08.40.60.01",firmware_version="08.40.60.01",name="e5660-02",nvsram_version="N5600-840834-D03",system="e5660-02"} 1
+	eseries_proxy_storage_system_firmware_info{app_version="",boot_version="",firmware_version="",name="",nvsram_version="",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 1
+	# HELP eseries_proxy_storage_system_status Status of storage system registered in the proxy, 1=current status 0=all other states
+	# TYPE eseries_proxy_storage_system_status gauge
+	eseries_proxy_storage_system_status{name="",status="lockDown",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	eseries_proxy_storage_system_status{name="",status="needsAttn",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	eseries_proxy_storage_system_status{name="",status="neverContacted",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 1
+	eseries_proxy_storage_system_status{name="",status="newDevice",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	eseries_proxy_storage_system_status{name="",status="offline",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	eseries_proxy_storage_system_status{name="",status="optimal",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	eseries_proxy_storage_system_status{name="",status="removed",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	eseries_proxy_storage_system_status{name="",status="unknown",system="f0d2fadc-3e16-46c5-b62e-c9ab6d430b50"} 0
+	eseries_proxy_storage_system_status{name="e5660-01",status="lockDown",system="e5660-01"} 0
+	eseries_proxy_storage_system_status{name="e5660-01",status="needsAttn",system="e5660-01"} 0
+	eseries_proxy_storage_system_status{name="e5660-01",status="neverContacted",system="e5660-01"} 0
+	eseries_proxy_storage_system_status{name="e5660-01",status="newDevice",system="e5660-01"} 0
+	eseries_proxy_storage_system_status{name="e5660-01",status="offline",system="e5660-01"} 0
+	eseries_proxy_storage_system_status{name="e5660-01",status="optimal",system="e5660-01"} 1
+	eseries_proxy_storage_system_status{name="e5660-01",status="removed",system="e5660-01"} 0
+	eseries_proxy_storage_system_status{name="e5660-01",status="unknown",system="e5660-01"} 0
+	eseries_proxy_storage_system_status{name="e5660-02",status="lockDown",system="e5660-02"} 0
+	eseries_proxy_storage_system_status{name="e5660-02",status="needsAttn",system="e5660-02"} 0
+	eseries_proxy_storage_system_status{name="e5660-02",status="neverContacted",system="e5660-02"} 0
+	eseries_proxy_storage_system_status{name="e5660-02",status="newDevice",system="e5660-02"} 0
+	eseries_proxy_storage_system_status{name="e5660-02",status="offline",system="e5660-02"} 1
+	eseries_proxy_storage_system_status{name="e5660-02",status="optimal",system="e5660-02"} 0
+	eseries_proxy_storage_system_status{name="e5660-02",status="removed",system="e5660-02"} 0
+	eseries_proxy_storage_system_status{name="e5660-02",status="unknown",system="e5660-02"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(fixtureData)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewProxyStorageSystemsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 43 {
+		t.Errorf("Unexpected collection count %d, expected 43", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_proxy_storage_system_status", "eseries_proxy_storage_system_controller_reachable",
+		"eseries_proxy_storage_system_firmware_info", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+	if val, err := testutil.GatherAndCount(gatherers, "eseries_proxy_storage_system_last_contacted_age_seconds"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 2 {
+		t.Errorf("Unexpected last contacted count %d, expected 2", val)
+	}
+}
+
+func TestProxyStorageSystemsCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="auth"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="connection"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="decode"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="other"} 0
+	eseries_exporter_collect_error{collector="proxy-storage-systems",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewProxyStorageSystemsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_proxy_storage_system_status", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
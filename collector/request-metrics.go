@@ -0,0 +1,69 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	proxyRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "exporter",
+		Name:      "proxy_request_duration_seconds",
+		Help:      "Duration of requests to the proxy API",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"endpoint", "module"})
+	proxyResponses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "exporter",
+		Name:      "proxy_responses_total",
+		Help:      "Responses from the proxy API, code is error when no response was received",
+	}, []string{"endpoint", "code", "module"})
+	proxyResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "exporter",
+		Name:      "proxy_response_size_bytes",
+		Help:      "Size of responses from the proxy API",
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+	}, []string{"endpoint", "module"})
+)
+
+// endpointTemplate replaces the storage system ID in path with {id} so
+// requests to all storage systems share the same label value
+func endpointTemplate(path string, id string) string {
+	if id == "" {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if s == id {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// observeRequest records a request, size is only recorded when a response body was read
+func observeRequest(module string, endpoint string, code string, start time.Time, size int) {
+	proxyRequestDuration.WithLabelValues(endpoint, module).Observe(time.Since(start).Seconds())
+	proxyResponses.WithLabelValues(endpoint, code, module).Inc()
+	if size >= 0 {
+		proxyResponseSize.WithLabelValues(endpoint, module).Observe(float64(size))
+	}
+}
@@ -0,0 +1,97 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestEndpointTemplate(t *testing.T) {
+	tests := []struct {
+		Path     string
+		ID       string
+		Expected string
+	}{
+		{Path: "/devmgr/v2/storage-systems/abc123/hardware-inventory", ID: "abc123", Expected: "/devmgr/v2/storage-systems/{id}/hardware-inventory"},
+		{Path: "/devmgr/v2/storage-systems/abc123", ID: "abc123", Expected: "/devmgr/v2/storage-systems/{id}"},
+		{Path: "/devmgr/v2/storage-systems", ID: "", Expected: "/devmgr/v2/storage-systems"},
+		{Path: "/devmgr/v2/storage-systems/abc/abc123-drives", ID: "abc", Expected: "/devmgr/v2/storage-systems/{id}/abc123-drives"},
+	}
+	for i, test := range tests {
+		if got := endpointTemplate(test.Path, test.ID); got != test.Expected {
+			t.Errorf("In case %d: got %s, expected %s", i, got, test.Expected)
+		}
+	}
+}
+
+func TestRequestMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/devmgr/v2/storage-systems/test/drives" {
+			_, _ = rw.Write([]byte("[]"))
+			return
+		}
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		Module:     "request-metrics",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	drives := proxyResponses.WithLabelValues("/devmgr/v2/storage-systems/{id}/drives", "200", "request-metrics")
+	volumes := proxyResponses.WithLabelValues("/devmgr/v2/storage-systems/{id}/volumes", "404", "request-metrics")
+	drivesBefore := testutil.ToFloat64(drives)
+	volumesBefore := testutil.ToFloat64(volumes)
+	for i := 0; i < 2; i++ {
+		if _, err := getRequest(context.Background(), target, "/devmgr/v2/storage-systems/test/drives", logger); err != nil {
+			t.Errorf("Unexpected error: %s", err.Error())
+		}
+	}
+	if _, err := getRequest(context.Background(), target, "/devmgr/v2/storage-systems/test/volumes", logger); err == nil {
+		t.Errorf("Expected error for missing endpoint")
+	}
+	if val := testutil.ToFloat64(drives) - drivesBefore; val != 2 {
+		t.Errorf("Unexpected responses %v for drives, expected 2", val)
+	}
+	if val := testutil.ToFloat64(volumes) - volumesBefore; val != 1 {
+		t.Errorf("Unexpected responses %v for volumes, expected 1", val)
+	}
+	if val := testutil.CollectAndCount(proxyResponseSize); val < 2 {
+		t.Errorf("Unexpected response size series count %d, expected at least 2", val)
+	}
+}
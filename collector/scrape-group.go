@@ -0,0 +1,158 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ScrapeGroup coalesces concurrent scrapes of the same module, target and
+// collectors so only one set of requests is made to the proxy
+type ScrapeGroup struct {
+	sync.Mutex
+	calls map[string]*scrapeCall
+}
+
+type scrapeCall struct {
+	done     chan struct{}
+	metrics  []prometheus.Metric
+	finished time.Time
+	ttl      time.Duration
+	canceled bool
+}
+
+type sharedCollector struct {
+	group      *ScrapeGroup
+	ctx        context.Context
+	key        string
+	ttl        time.Duration
+	collectors map[string]Collector
+}
+
+func NewScrapeGroup() *ScrapeGroup {
+	return &ScrapeGroup{calls: make(map[string]*scrapeCall)}
+}
+
+// ScrapeKey identifies scrapes that produce the same metrics
+func ScrapeKey(module string, target string, collectors map[string]Collector) string {
+	var names []string
+	for name := range collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return module + "/" + target + "/" + strings.Join(names, ",")
+}
+
+// Collector returns a collector that shares the results of collectors with
+// concurrent scrapes of key and reuses them for ttl once complete, ctx is
+// the context of the scrape the collectors were created with
+func (g *ScrapeGroup) Collector(ctx context.Context, key string, ttl time.Duration, collectors map[string]Collector) Collector {
+	return &sharedCollector{group: g, ctx: ctx, key: key, ttl: ttl, collectors: collectors}
+}
+
+// do returns the result of the running or cached call of key or runs fn,
+// false is returned if ctx is done before a result is available
+func (g *ScrapeGroup) do(ctx context.Context, key string, ttl time.Duration, fn func() []prometheus.Metric) ([]prometheus.Metric, bool) {
+	for {
+		g.Lock()
+		g.sweep()
+		c, ok := g.calls[key]
+		if !ok {
+			break
+		}
+		if !c.finished.IsZero() {
+			g.Unlock()
+			return c.metrics, true
+		}
+		g.Unlock()
+		select {
+		case <-c.done:
+			// The result of a leader that was cut off is not shared, retry
+			// so another call of key runs with its own context
+			if !c.canceled {
+				return c.metrics, true
+			}
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	c := &scrapeCall{done: make(chan struct{}), ttl: ttl}
+	g.calls[key] = c
+	g.Unlock()
+
+	c.metrics = fn()
+
+	g.Lock()
+	c.finished = time.Now()
+	c.canceled = ctx.Err() != nil
+	if c.canceled || ttl == 0 {
+		delete(g.calls, key)
+	}
+	g.Unlock()
+	close(c.done)
+	return c.metrics, true
+}
+
+// sweep removes calls whose results are older than their ttl
+func (g *ScrapeGroup) sweep() {
+	for key, c := range g.calls {
+		if !c.finished.IsZero() && time.Since(c.finished) >= c.ttl {
+			delete(g.calls, key)
+		}
+	}
+}
+
+func (c *sharedCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors {
+		collector.Describe(ch)
+	}
+}
+
+func (c *sharedCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics, ok := c.group.do(c.ctx, c.key, c.ttl, func() []prometheus.Metric {
+		metricCh := make(chan prometheus.Metric)
+		wg := &sync.WaitGroup{}
+		wg.Add(len(c.collectors))
+		for _, collector := range c.collectors {
+			go func(collector Collector) {
+				defer wg.Done()
+				collector.Collect(metricCh)
+			}(collector)
+		}
+		go func() {
+			wg.Wait()
+			close(metricCh)
+		}()
+		var metrics []prometheus.Metric
+		for m := range metricCh {
+			metrics = append(metrics, m)
+		}
+		return metrics
+	})
+	if !ok {
+		for name := range c.collectors {
+			ch <- prometheus.MustNewConstMetric(collectTimeout, prometheus.GaugeValue, 1, name)
+		}
+		return
+	}
+	for _, m := range metrics {
+		ch <- m
+	}
+}
@@ -0,0 +1,160 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestScrapeGroup(t *testing.T) {
+	fixtureData, err := os.ReadFile("testdata/drives.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = rw.Write(fixtureData)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+		Collectors: []string{"drives"},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	group := NewScrapeGroup()
+	newCollector := func(ttl time.Duration) Collector {
+		collectors := NewCollector(context.Background(), target, logger).Collectors
+		return group.Collector(context.Background(), ScrapeKey("default", "test", collectors), ttl, collectors)
+	}
+
+	wg := &sync.WaitGroup{}
+	counts := make([]int, 2)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counts[i], _ = testutil.GatherAndCount(setupGatherer(newCollector(0)))
+		}(i)
+	}
+	wg.Wait()
+	if val := atomic.LoadInt32(&requests); val != 1 {
+		t.Errorf("Unexpected number of requests for concurrent scrapes, expected 1, got %d", val)
+	}
+	for i, count := range counts {
+		if count != 43 {
+			t.Errorf("Unexpected collection count %d for scrape %d, expected 43", count, i)
+		}
+	}
+
+	if _, err := testutil.GatherAndCount(setupGatherer(newCollector(0))); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if val := atomic.LoadInt32(&requests); val != 2 {
+		t.Errorf("Unexpected number of requests without TTL, expected 2, got %d", val)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := testutil.GatherAndCount(setupGatherer(newCollector(time.Hour))); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if val := atomic.LoadInt32(&requests); val != 3 {
+		t.Errorf("Unexpected number of requests with TTL, expected 3, got %d", val)
+	}
+}
+
+func TestScrapeGroupCanceled(t *testing.T) {
+	group := NewScrapeGroup()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		group.do(leaderCtx, "test", time.Hour, func() []prometheus.Metric {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	waiterCtx, cancelWaiter := context.WithCancel(context.Background())
+	cancelWaiter()
+	if _, ok := group.do(waiterCtx, "test", time.Hour, func() []prometheus.Metric {
+		t.Errorf("Unexpected call while leader is running")
+		return nil
+	}); ok {
+		t.Errorf("Expected waiter with canceled context to return without a result")
+	}
+
+	cancelLeader()
+	close(release)
+	<-leaderDone
+	calls := 0
+	group.do(context.Background(), "test", time.Hour, func() []prometheus.Metric {
+		calls++
+		return nil
+	})
+	if calls != 1 {
+		t.Errorf("Expected result of canceled leader to not be cached, got %d calls", calls)
+	}
+}
+
+func TestScrapeGroupSweep(t *testing.T) {
+	group := NewScrapeGroup()
+	fn := func() []prometheus.Metric { return nil }
+	group.do(context.Background(), "short", time.Millisecond, fn)
+	group.do(context.Background(), "long", time.Hour, fn)
+	time.Sleep(10 * time.Millisecond)
+	group.do(context.Background(), "other", 0, fn)
+	group.Lock()
+	defer group.Unlock()
+	if _, ok := group.calls["short"]; ok {
+		t.Errorf("Expected expired call to be removed")
+	}
+	if _, ok := group.calls["long"]; !ok {
+		t.Errorf("Expected call within TTL to be kept")
+	}
+}
@@ -14,6 +14,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -34,27 +35,77 @@ var (
 		"newDevice",
 		"lockDown",
 	}
+	keyManagementModes = []string{"none", "internal", "external"}
 )
 
 type StorageSystem struct {
-	ID     string `json:"id"`
-	Status string `json:"status"`
+	ID                  string                    `json:"id"`
+	Name                string                    `json:"name"`
+	Status              string                    `json:"status"`
+	WWN                 string                    `json:"wwn"`
+	ChassisSerialNumber string                    `json:"chassisSerialNumber"`
+	Model               string                    `json:"model"`
+	UsedPoolSpace       float64                   `json:"usedPoolSpace,string"`
+	FreePoolSpace       float64                   `json:"freePoolSpace,string"`
+	UnconfiguredSpace   float64                   `json:"unconfiguredSpace,string"`
+	HotSpareCount       float64                   `json:"hotSpareCount"`
+	MediaScanPeriod     float64                   `json:"mediaScanPeriod"`
+	SecurityKeyEnabled  bool                      `json:"securityKeyEnabled"`
+	ExternalKeyEnabled  bool                      `json:"externalKeyEnabled"`
+	LastContacted       string                    `json:"lastContacted"`
+	FwVersion           string                    `json:"fwVersion"`
+	AppVersion          string                    `json:"appVersion"`
+	BootVersion         string                    `json:"bootVersion"`
+	NvsramVersion       string                    `json:"nvsramVersion"`
+	Controllers         []StorageSystemController `json:"controllers"`
+	ManagementPaths     []string                  `json:"managementPaths"`
+}
+
+type StorageSystemController struct {
+	ControllerID string   `json:"controllerId"`
+	IPAddresses  []string `json:"ipAddresses"`
 }
 
 type StorageSystemsCollector struct {
-	Status *prometheus.Desc
-	target config.Target
-	logger log.Logger
+	Status            *prometheus.Desc
+	Info              *prometheus.Desc
+	Controllers       *prometheus.Desc
+	UsedPoolSpace     *prometheus.Desc
+	FreePoolSpace     *prometheus.Desc
+	UnconfiguredSpace *prometheus.Desc
+	HotSpareCount     *prometheus.Desc
+	SecurityKey       *prometheus.Desc
+	KeyManagement     *prometheus.Desc
+	ctx               context.Context
+	target            config.Target
+	logger            log.Logger
 }
 
 func init() {
 	registerCollector("storage-systems", true, NewStorageSystemsExporter)
 }
 
-func NewStorageSystemsExporter(target config.Target, logger log.Logger) Collector {
+func NewStorageSystemsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
 	return &StorageSystemsCollector{
 		Status: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "status"),
 			"Storage System status, 1=optimal 0=all other states", []string{"status"}, nil),
+		Info: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "info"),
+			"Storage System information", []string{"name", "wwn", "chassis_serial", "model", "firmware"}, nil),
+		Controllers: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "controllers"),
+			"Storage System controller count", nil, nil),
+		UsedPoolSpace: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "used_pool_space_bytes"),
+			"Storage System used pool space in bytes", nil, nil),
+		FreePoolSpace: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "free_pool_space_bytes"),
+			"Storage System free pool space in bytes", nil, nil),
+		UnconfiguredSpace: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "unconfigured_space_bytes"),
+			"Storage System unconfigured space in bytes", nil, nil),
+		HotSpareCount: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "hot_spares"),
+			"Storage System hot spare drive count", nil, nil),
+		SecurityKey: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "security_key_installed"),
+			"Storage System has a drive security key installed, 1=installed 0=not installed", nil, nil),
+		KeyManagement: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_system", "key_management_mode"),
+			"Storage System drive security key management mode", []string{"mode"}, nil),
+		ctx:    ctx,
 		target: target,
 		logger: logger,
 	}
@@ -62,16 +113,24 @@ func NewStorageSystemsExporter(target config.Target, logger log.Logger) Collecto
 
 func (c *StorageSystemsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Status
+	ch <- c.Info
+	ch <- c.Controllers
+	ch <- c.UsedPoolSpace
+	ch <- c.FreePoolSpace
+	ch <- c.UnconfiguredSpace
+	ch <- c.HotSpareCount
+	ch <- c.SecurityKey
+	ch <- c.KeyManagement
 }
 
 func (c *StorageSystemsCollector) Collect(ch chan<- prometheus.Metric) {
 	level.Debug(c.logger).Log("msg", "Collecting storage-systems metrics")
 	collectTime := time.Now()
-	var errorMetric int
+	var errorReason string
 	metric, err := c.collect()
 	if err != nil {
 		level.Error(c.logger).Log("msg", err)
-		errorMetric = 1
+		errorReason = errorReasonFor(err)
 	}
 
 	if err == nil {
@@ -87,14 +146,35 @@ func (c *StorageSystemsCollector) Collect(ch chan<- prometheus.Metric) {
 			unknown = 1
 		}
 		ch <- prometheus.MustNewConstMetric(c.Status, prometheus.GaugeValue, unknown, "unknown")
+		ch <- prometheus.MustNewConstMetric(c.Info, prometheus.GaugeValue, 1, metric.Name, metric.WWN,
+			metric.ChassisSerialNumber, metric.Model, metric.FwVersion)
+		ch <- prometheus.MustNewConstMetric(c.Controllers, prometheus.GaugeValue, float64(len(metric.Controllers)))
+		ch <- prometheus.MustNewConstMetric(c.UsedPoolSpace, prometheus.GaugeValue, metric.UsedPoolSpace)
+		ch <- prometheus.MustNewConstMetric(c.FreePoolSpace, prometheus.GaugeValue, metric.FreePoolSpace)
+		ch <- prometheus.MustNewConstMetric(c.UnconfiguredSpace, prometheus.GaugeValue, metric.UnconfiguredSpace)
+		ch <- prometheus.MustNewConstMetric(c.HotSpareCount, prometheus.GaugeValue, metric.HotSpareCount)
+		ch <- prometheus.MustNewConstMetric(c.SecurityKey, prometheus.GaugeValue, boolToFloat64(metric.SecurityKeyEnabled))
+		keyManagementMode := "none"
+		if metric.ExternalKeyEnabled {
+			keyManagementMode = "external"
+		} else if metric.SecurityKeyEnabled {
+			keyManagementMode = "internal"
+		}
+		for _, mode := range keyManagementModes {
+			var value float64
+			if mode == keyManagementMode {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.KeyManagement, prometheus.GaugeValue, value, mode)
+		}
 	}
-	ch <- prometheus.MustNewConstMetric(collectError, prometheus.GaugeValue, float64(errorMetric), "storage-systems")
+	collectErrorMetrics(ch, "storage-systems", errorReason)
 	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "storage-systems")
 }
 
 func (c *StorageSystemsCollector) collect() (StorageSystem, error) {
 	var metrics StorageSystem
-	body, err := getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s", c.target.Name), c.logger)
+	body, err := getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s", c.target.Name), c.logger)
 	if err != nil {
 		return metrics, err
 	}
@@ -23,6 +23,7 @@
 package collector
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -41,9 +42,35 @@ func TestStorageSystemCollector(t *testing.T) {
 		t.Fatalf("Error loading fixture data: %s", err.Error())
 	}
 	expected := `
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="storage-systems"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="auth"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="connection"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="decode"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="other"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="timeout"} 0
+	# HELP eseries_storage_system_controllers Storage System controller count
+	# TYPE eseries_storage_system_controllers gauge
+	eseries_storage_system_controllers 2
+	# HELP eseries_storage_system_free_pool_space_bytes Storage System free pool space in bytes
+	# TYPE eseries_storage_system_free_pool_space_bytes gauge
+	eseries_storage_system_free_pool_space_bytes 2.19043332096e+12
+	# HELP eseries_storage_system_hot_spares Storage System hot spare drive count
+	# TYPE eseries_storage_system_hot_spares gauge
+	eseries_storage_system_hot_spares 0
+	# HELP eseries_storage_system_info Storage System information
+	# TYPE eseries_storage_system_info gauge
+	eseries_storage_system_info{chassis_serial="721551500105",firmware="08.40.50.00",model="5600",name="e5660-01",wwn="60080E500043A1B00000000056D6B726"} 1
+	# HELP eseries_storage_system_key_management_mode Storage System drive security key management mode
+	# TYPE eseries_storage_system_key_management_mode gauge
+	eseries_storage_system_key_management_mode{mode="external"} 0
+	eseries_storage_system_key_management_mode{mode="internal"} 1
+	eseries_storage_system_key_management_mode{mode="none"} 0
+	# HELP eseries_storage_system_security_key_installed Storage System has a drive security key installed, 1=installed 0=not installed
+	# TYPE eseries_storage_system_security_key_installed gauge
+	eseries_storage_system_security_key_installed 1
 	# HELP eseries_storage_system_status Storage System status, 1=optimal 0=all other states
 	# TYPE eseries_storage_system_status gauge
 	eseries_storage_system_status{status="lockDown"} 0
@@ -54,6 +81,12 @@ func TestStorageSystemCollector(t *testing.T) {
 	eseries_storage_system_status{status="optimal"} 1
 	eseries_storage_system_status{status="removed"} 0
 	eseries_storage_system_status{status="unknown"} 0
+	# HELP eseries_storage_system_unconfigured_space_bytes Storage System unconfigured space in bytes
+	# TYPE eseries_storage_system_unconfigured_space_bytes gauge
+	eseries_storage_system_unconfigured_space_bytes 0
+	# HELP eseries_storage_system_used_pool_space_bytes Storage System used pool space in bytes
+	# TYPE eseries_storage_system_used_pool_space_bytes gauge
+	eseries_storage_system_used_pool_space_bytes 5.44490183983104e+14
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		_, _ = rw.Write(fixtureData)
@@ -69,24 +102,34 @@ func TestStorageSystemCollector(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewStorageSystemsExporter(target, logger)
+	collector := NewStorageSystemsExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 10 {
-		t.Errorf("Unexpected collection count %d, expected 10", val)
+	} else if val != 26 {
+		t.Errorf("Unexpected collection count %d, expected 26", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
-		"eseries_storage_system_status", "eseries_exporter_collect_error"); err != nil {
+		"eseries_storage_system_status", "eseries_storage_system_info", "eseries_storage_system_controllers",
+		"eseries_storage_system_used_pool_space_bytes", "eseries_storage_system_free_pool_space_bytes",
+		"eseries_storage_system_unconfigured_space_bytes", "eseries_storage_system_hot_spares",
+		"eseries_storage_system_security_key_installed", "eseries_storage_system_key_management_mode",
+		"eseries_exporter_collect_error"); err != nil {
 		t.Errorf("unexpected collecting result:\n%s", err)
 	}
 }
 
 func TestStorageSystemCollectorError(t *testing.T) {
 	expected := `
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="storage-systems"} 1
+	eseries_exporter_collect_error{collector="storage-systems",reason="auth"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="connection"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="decode"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="storage-systems",reason="other"} 0
+	eseries_exporter_collect_error{collector="storage-systems",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		http.Error(rw, "error", http.StatusNotFound)
@@ -102,12 +145,12 @@ func TestStorageSystemCollectorError(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewStorageSystemsExporter(target, logger)
+	collector := NewStorageSystemsExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 2 {
-		t.Errorf("Unexpected collection count %d, expected 2", val)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_storage_system_status", "eseries_exporter_collect_error"); err != nil {
@@ -14,6 +14,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -52,6 +53,7 @@ type SystemStatisticsCollector struct {
 	WriteHitResponseTime    *prometheus.Desc
 	WritePhysicalIOps       *prometheus.Desc
 	WriteResponseTime       *prometheus.Desc
+	ctx                     context.Context
 	target                  config.Target
 	logger                  log.Logger
 }
@@ -60,7 +62,7 @@ func init() {
 	registerCollector("system-statistics", true, NewSystemStatisticsExporter)
 }
 
-func NewSystemStatisticsExporter(target config.Target, logger log.Logger) Collector {
+func NewSystemStatisticsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
 	return &SystemStatisticsCollector{
 		AverageReadOpSize: prometheus.NewDesc(prometheus.BuildFQName(namespace, "system", "average_read_op_size_bytes"),
 			"System statistic averageReadOpSize", nil, nil),
@@ -86,6 +88,7 @@ func NewSystemStatisticsExporter(target config.Target, logger log.Logger) Collec
 			"System statistic writePhysicalIOps", nil, nil),
 		WriteResponseTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "system", "write_response_time_seconds"),
 			"System statistic writeResponseTime", nil, nil),
+		ctx:    ctx,
 		target: target,
 		logger: logger,
 	}
@@ -109,11 +112,11 @@ func (c *SystemStatisticsCollector) Describe(ch chan<- *prometheus.Desc) {
 func (c *SystemStatisticsCollector) Collect(ch chan<- prometheus.Metric) {
 	level.Debug(c.logger).Log("msg", "Collecting system-statistics metrics")
 	collectTime := time.Now()
-	var errorMetric int
+	var errorReason string
 	statistics, err := c.collect()
 	if err != nil {
 		level.Error(c.logger).Log("msg", err)
-		errorMetric = 1
+		errorReason = errorReasonFor(err)
 	}
 
 	if err == nil {
@@ -131,13 +134,13 @@ func (c *SystemStatisticsCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.WriteResponseTime, prometheus.GaugeValue, statistics.WriteResponseTime)
 	}
 
-	ch <- prometheus.MustNewConstMetric(collectError, prometheus.GaugeValue, float64(errorMetric), "system-statistics")
+	collectErrorMetrics(ch, "system-statistics", errorReason)
 	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "system-statistics")
 }
 
 func (c *SystemStatisticsCollector) collect() (SystemStatistics, error) {
 	var statistics SystemStatistics
-	statisticsBody, err := getRequest(c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/analysed-system-statistics", c.target.Name), c.logger)
+	statisticsBody, err := getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/analysed-system-statistics", c.target.Name), c.logger)
 	if err != nil {
 		return statistics, err
 	}
@@ -23,6 +23,7 @@
 package collector
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -41,9 +42,15 @@ func TestSystemStatisticsCollector(t *testing.T) {
 		t.Fatalf("Error loading fixture data: %s", err.Error())
 	}
 	expected := `
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="system-statistics"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="auth"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="connection"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="decode"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="other"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="timeout"} 0
 	# HELP eseries_system_average_read_op_size_bytes System statistic averageReadOpSize
 	# TYPE eseries_system_average_read_op_size_bytes gauge
 	eseries_system_average_read_op_size_bytes 17357.11013434037
@@ -62,12 +69,12 @@ func TestSystemStatisticsCollector(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewSystemStatisticsExporter(target, logger)
+	collector := NewSystemStatisticsExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 14 {
-		t.Errorf("Unexpected collection count %d, expected 14", val)
+	} else if val != 20 {
+		t.Errorf("Unexpected collection count %d, expected 20", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		/*
@@ -86,9 +93,15 @@ func TestSystemStatisticsCollector(t *testing.T) {
 
 func TestSystemStatisticsCollectorError(t *testing.T) {
 	expected := `
-	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
 	# TYPE eseries_exporter_collect_error gauge
-	eseries_exporter_collect_error{collector="system-statistics"} 1
+	eseries_exporter_collect_error{collector="system-statistics",reason="auth"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="connection"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="decode"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="system-statistics",reason="other"} 0
+	eseries_exporter_collect_error{collector="system-statistics",reason="timeout"} 0
 	`
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		http.Error(rw, "error", http.StatusNotFound)
@@ -104,12 +117,12 @@ func TestSystemStatisticsCollectorError(t *testing.T) {
 	}
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	collector := NewSystemStatisticsExporter(target, logger)
+	collector := NewSystemStatisticsExporter(context.Background(), target, logger)
 	gatherers := setupGatherer(collector)
 	if val, err := testutil.GatherAndCount(gatherers); err != nil {
 		t.Errorf("Unexpected error: %v", err)
-	} else if val != 2 {
-		t.Errorf("Unexpected collection count %d, expected 2", val)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
 	}
 	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
 		"eseries_system_average_read_op_size_bytes", "eseries_exporter_collect_error"); err != nil {
@@ -0,0 +1,188 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type Volume struct {
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	Capacity         float64          `json:"capacity,string"`
+	CurrentManager   string           `json:"currentManager"`
+	PreferredManager string           `json:"preferredManager"`
+	MediaScan        VolumeMediaScan  `json:"mediaScan"`
+	Metadata         []VolumeMetadata `json:"metadata"`
+}
+
+type VolumeMediaScan struct {
+	Enable                 bool `json:"enable"`
+	ParityValidationEnable bool `json:"parityValidationEnable"`
+}
+
+type VolumeMetadata struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type VolumeOwnerMetric struct {
+	Volume                   string
+	CurrentControllerLabel   string
+	PreferredControllerLabel string
+	PreferredPath            bool
+}
+
+type ControllerVolumesMetric struct {
+	Controller      string
+	ControllerLabel string
+	Volumes         float64
+}
+
+type VolumesMetrics struct {
+	Volumes           []VolumeOwnerMetric
+	Controllers       []ControllerVolumesMetric
+	NonPreferredPaths float64
+}
+
+type VolumesCollector struct {
+	OwnerInfo         *prometheus.Desc
+	PreferredPath     *prometheus.Desc
+	NonPreferredPaths *prometheus.Desc
+	ControllerVolumes *prometheus.Desc
+	ctx               context.Context
+	target            config.Target
+	logger            log.Logger
+}
+
+func init() {
+	registerCollector("volumes", false, NewVolumesExporter)
+}
+
+func NewVolumesExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	return &VolumesCollector{
+		OwnerInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, "volume", "owner_info"),
+			"Current and preferred owning controller of volume", []string{"volume", "current_controller_label", "preferred_controller_label"}, nil),
+		PreferredPath: prometheus.NewDesc(prometheus.BuildFQName(namespace, "volume", "preferred_path"),
+			"Volume is owned by its preferred controller, 1=preferred 0=non-preferred", []string{"volume"}, nil),
+		NonPreferredPaths: prometheus.NewDesc(prometheus.BuildFQName(namespace, "volumes", "non_preferred_path"),
+			"Number of volumes not owned by their preferred controller", nil, nil),
+		ControllerVolumes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "controller", "volumes"),
+			"Number of volumes currently owned by controller", []string{"controller", "controller_label"}, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *VolumesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.OwnerInfo
+	ch <- c.PreferredPath
+	ch <- c.NonPreferredPaths
+	ch <- c.ControllerVolumes
+}
+
+func (c *VolumesCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting volumes metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	if err == nil {
+		for _, v := range metrics.Volumes {
+			ch <- prometheus.MustNewConstMetric(c.OwnerInfo, prometheus.GaugeValue, 1, v.Volume, v.CurrentControllerLabel, v.PreferredControllerLabel)
+			ch <- prometheus.MustNewConstMetric(c.PreferredPath, prometheus.GaugeValue, boolToFloat64(v.PreferredPath), v.Volume)
+		}
+		for _, controller := range metrics.Controllers {
+			ch <- prometheus.MustNewConstMetric(c.ControllerVolumes, prometheus.GaugeValue, controller.Volumes, controller.Controller, controller.ControllerLabel)
+		}
+		ch <- prometheus.MustNewConstMetric(c.NonPreferredPaths, prometheus.GaugeValue, metrics.NonPreferredPaths)
+	}
+
+	collectErrorMetrics(ch, "volumes", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "volumes")
+}
+
+func (c *VolumesCollector) collect() (VolumesMetrics, error) {
+	var metrics VolumesMetrics
+	var inventory ControllersInventory
+	var volumes []Volume
+	var inventoryBody, volumesBody []byte
+	var inventoryErr, volumesErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		inventoryBody, inventoryErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/hardware-inventory", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		volumesBody, volumesErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/volumes", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if inventoryErr != nil {
+		return metrics, inventoryErr
+	}
+	if volumesErr != nil {
+		return metrics, volumesErr
+	}
+	err := json.Unmarshal(inventoryBody, &inventory)
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal(volumesBody, &volumes)
+	if err != nil {
+		return metrics, err
+	}
+
+	controllerLabels := make(map[string]string)
+	for _, controller := range inventory.Controllers {
+		controllerLabels[controller.ID] = controller.PhysicalLocation.Label
+	}
+	controllerVolumes := make(map[string]float64)
+	for _, v := range volumes {
+		preferred := v.CurrentManager == v.PreferredManager
+		if !preferred {
+			metrics.NonPreferredPaths++
+		}
+		controllerVolumes[v.CurrentManager]++
+		metrics.Volumes = append(metrics.Volumes, VolumeOwnerMetric{
+			Volume:                   v.Name,
+			CurrentControllerLabel:   controllerLabels[v.CurrentManager],
+			PreferredControllerLabel: controllerLabels[v.PreferredManager],
+			PreferredPath:            preferred,
+		})
+	}
+	for _, controller := range inventory.Controllers {
+		metrics.Controllers = append(metrics.Controllers, ControllerVolumesMetric{
+			Controller:      controller.ID,
+			ControllerLabel: controller.PhysicalLocation.Label,
+			Volumes:         controllerVolumes[controller.ID],
+		})
+	}
+	return metrics, nil
+}
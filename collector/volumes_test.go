@@ -0,0 +1,147 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestVolumesCollector(t *testing.T) {
+	inventoryData, err := os.ReadFile("testdata/controllers.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	volumesData, err := os.ReadFile("testdata/volumes.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_controller_volumes Number of volumes currently owned by controller
+	# TYPE eseries_controller_volumes gauge
+	eseries_controller_volumes{controller="070000000000000000000001",controller_label="A"} 1
+	eseries_controller_volumes{controller="070000000000000000000002",controller_label="B"} 3
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="volumes",reason="auth"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="connection"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="decode"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="other"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="timeout"} 0
+	# HELP eseries_volume_owner_info Current and preferred owning controller of volume
+	# TYPE eseries_volume_owner_info gauge
+	eseries_volume_owner_info{current_controller_label="A",preferred_controller_label="A",volume="home"} 1
+	eseries_volume_owner_info{current_controller_label="B",preferred_controller_label="A",volume="apps"} 1
+	eseries_volume_owner_info{current_controller_label="B",preferred_controller_label="A",volume="scratch"} 1
+	eseries_volume_owner_info{current_controller_label="B",preferred_controller_label="B",volume="project"} 1
+	# HELP eseries_volume_preferred_path Volume is owned by its preferred controller, 1=preferred 0=non-preferred
+	# TYPE eseries_volume_preferred_path gauge
+	eseries_volume_preferred_path{volume="apps"} 0
+	eseries_volume_preferred_path{volume="home"} 1
+	eseries_volume_preferred_path{volume="project"} 1
+	eseries_volume_preferred_path{volume="scratch"} 0
+	# HELP eseries_volumes_non_preferred_path Number of volumes not owned by their preferred controller
+	# TYPE eseries_volumes_non_preferred_path gauge
+	eseries_volumes_non_preferred_path 2
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "hardware-inventory") {
+			_, _ = rw.Write(inventoryData)
+		} else {
+			_, _ = rw.Write(volumesData)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewVolumesExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 19 {
+		t.Errorf("Unexpected collection count %d, expected 19", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_volume_owner_info", "eseries_volume_preferred_path", "eseries_volumes_non_preferred_path",
+		"eseries_controller_volumes", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestVolumesCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="volumes",reason="auth"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="connection"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="decode"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="volumes",reason="other"} 0
+	eseries_exporter_collect_error{collector="volumes",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewVolumesExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_volume_preferred_path", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
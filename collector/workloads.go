@@ -0,0 +1,255 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+type Workload struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type AnalysedVolumeStatistics struct {
+	ID                   string  `json:"volumeId"`
+	ReadIOps             float64 `json:"readIOps"`
+	WriteIOps            float64 `json:"writeIOps"`
+	CombinedIOps         float64 `json:"combinedIOps"`
+	ReadThroughput       float64 `json:"readThroughput"`
+	WriteThroughput      float64 `json:"writeThroughput"`
+	CombinedThroughput   float64 `json:"combinedThroughput"`
+	ReadResponseTime     float64 `json:"readResponseTime"`
+	WriteResponseTime    float64 `json:"writeResponseTime"`
+	CombinedResponseTime float64 `json:"combinedResponseTime"`
+}
+
+type WorkloadMetrics struct {
+	Name                 string
+	Volumes              float64
+	Capacity             float64
+	ReadIOps             float64
+	WriteIOps            float64
+	CombinedIOps         float64
+	ReadThroughput       float64
+	WriteThroughput      float64
+	CombinedThroughput   float64
+	ReadResponseTime     float64
+	WriteResponseTime    float64
+	CombinedResponseTime float64
+}
+
+type WorkloadsCollector struct {
+	Volumes              *prometheus.Desc
+	Capacity             *prometheus.Desc
+	ReadIOps             *prometheus.Desc
+	WriteIOps            *prometheus.Desc
+	CombinedIOps         *prometheus.Desc
+	ReadThroughput       *prometheus.Desc
+	WriteThroughput      *prometheus.Desc
+	CombinedThroughput   *prometheus.Desc
+	ReadResponseTime     *prometheus.Desc
+	WriteResponseTime    *prometheus.Desc
+	CombinedResponseTime *prometheus.Desc
+	ctx                  context.Context
+	target               config.Target
+	logger               log.Logger
+}
+
+func init() {
+	registerCollector("workloads", false, NewWorkloadsExporter)
+}
+
+func NewWorkloadsExporter(ctx context.Context, target config.Target, logger log.Logger) Collector {
+	labels := []string{"workload"}
+	return &WorkloadsCollector{
+		Volumes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "volumes"),
+			"Number of volumes tagged with workload", labels, nil),
+		Capacity: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "capacity_bytes"),
+			"Capacity of volumes tagged with workload", labels, nil),
+		ReadIOps: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "read_iops"),
+			"Sum of volume statistic readIOps for workload", labels, nil),
+		WriteIOps: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "write_iops"),
+			"Sum of volume statistic writeIOps for workload", labels, nil),
+		CombinedIOps: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "iops"),
+			"Sum of volume statistic combinedIOps for workload", labels, nil),
+		ReadThroughput: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "read_throughput_bytes_per_second"),
+			"Sum of volume statistic readThroughput for workload", labels, nil),
+		WriteThroughput: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "write_throughput_bytes_per_second"),
+			"Sum of volume statistic writeThroughput for workload", labels, nil),
+		CombinedThroughput: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "throughput_bytes_per_second"),
+			"Sum of volume statistic combinedThroughput for workload", labels, nil),
+		ReadResponseTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "read_response_time_seconds"),
+			"IOPS weighted average of volume statistic readResponseTime for workload", labels, nil),
+		WriteResponseTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "write_response_time_seconds"),
+			"IOPS weighted average of volume statistic writeResponseTime for workload", labels, nil),
+		CombinedResponseTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "workload", "combined_response_time_seconds"),
+			"IOPS weighted average of volume statistic combinedResponseTime for workload", labels, nil),
+		ctx:    ctx,
+		target: target,
+		logger: logger,
+	}
+}
+
+func (c *WorkloadsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Volumes
+	ch <- c.Capacity
+	ch <- c.ReadIOps
+	ch <- c.WriteIOps
+	ch <- c.CombinedIOps
+	ch <- c.ReadThroughput
+	ch <- c.WriteThroughput
+	ch <- c.CombinedThroughput
+	ch <- c.ReadResponseTime
+	ch <- c.WriteResponseTime
+	ch <- c.CombinedResponseTime
+}
+
+func (c *WorkloadsCollector) Collect(ch chan<- prometheus.Metric) {
+	level.Debug(c.logger).Log("msg", "Collecting workloads metrics")
+	collectTime := time.Now()
+	var errorReason string
+	metrics, err := c.collect()
+	if err != nil {
+		level.Error(c.logger).Log("msg", err)
+		errorReason = errorReasonFor(err)
+	}
+
+	for _, m := range metrics {
+		ch <- prometheus.MustNewConstMetric(c.Volumes, prometheus.GaugeValue, m.Volumes, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.Capacity, prometheus.GaugeValue, m.Capacity, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.ReadIOps, prometheus.GaugeValue, m.ReadIOps, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.WriteIOps, prometheus.GaugeValue, m.WriteIOps, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.CombinedIOps, prometheus.GaugeValue, m.CombinedIOps, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.ReadThroughput, prometheus.GaugeValue, m.ReadThroughput, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.WriteThroughput, prometheus.GaugeValue, m.WriteThroughput, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.CombinedThroughput, prometheus.GaugeValue, m.CombinedThroughput, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.ReadResponseTime, prometheus.GaugeValue, m.ReadResponseTime, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.WriteResponseTime, prometheus.GaugeValue, m.WriteResponseTime, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.CombinedResponseTime, prometheus.GaugeValue, m.CombinedResponseTime, m.Name)
+	}
+
+	collectErrorMetrics(ch, "workloads", errorReason)
+	ch <- prometheus.MustNewConstMetric(collectDuration, prometheus.GaugeValue, time.Since(collectTime).Seconds(), "workloads")
+}
+
+func (c *WorkloadsCollector) collect() ([]WorkloadMetrics, error) {
+	var workloads []Workload
+	var volumes []Volume
+	var statistics []AnalysedVolumeStatistics
+	var workloadsBody, volumesBody, statisticsBody []byte
+	var workloadsErr, volumesErr, statisticsErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		workloadsBody, workloadsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/workloads", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		volumesBody, volumesErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/volumes", c.target.Name), c.logger)
+	}()
+	go func() {
+		defer wg.Done()
+		statisticsBody, statisticsErr = getRequest(c.ctx, c.target, fmt.Sprintf("/devmgr/v2/storage-systems/%s/analysed-volume-statistics", c.target.Name), c.logger)
+	}()
+	wg.Wait()
+	if workloadsErr != nil {
+		return nil, workloadsErr
+	}
+	if volumesErr != nil {
+		return nil, volumesErr
+	}
+	if statisticsErr != nil {
+		return nil, statisticsErr
+	}
+	err := json.Unmarshal(workloadsBody, &workloads)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(volumesBody, &volumes)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(statisticsBody, &statistics)
+	if err != nil {
+		return nil, err
+	}
+	volumeStatistics := make(map[string]AnalysedVolumeStatistics)
+	for _, s := range statistics {
+		volumeStatistics[s.ID] = s
+	}
+	metrics := make([]WorkloadMetrics, len(workloads))
+	workloadIndex := make(map[string]int)
+	for i, w := range workloads {
+		metrics[i].Name = w.Name
+		workloadIndex[w.ID] = i
+	}
+	for _, v := range volumes {
+		var workloadID string
+		for _, m := range v.Metadata {
+			if m.Key == "workloadId" {
+				workloadID = m.Value
+			}
+		}
+		i, ok := workloadIndex[workloadID]
+		if !ok {
+			continue
+		}
+		m := &metrics[i]
+		m.Volumes++
+		m.Capacity += v.Capacity
+		s, ok := volumeStatistics[v.ID]
+		if !ok {
+			continue
+		}
+		m.ReadIOps += s.ReadIOps
+		m.WriteIOps += s.WriteIOps
+		m.CombinedIOps += s.CombinedIOps
+		m.ReadThroughput += s.ReadThroughput
+		m.WriteThroughput += s.WriteThroughput
+		m.CombinedThroughput += s.CombinedThroughput
+		m.ReadResponseTime += s.ReadResponseTime * s.ReadIOps
+		m.WriteResponseTime += s.WriteResponseTime * s.WriteIOps
+		m.CombinedResponseTime += s.CombinedResponseTime * s.CombinedIOps
+	}
+	for i := range metrics {
+		m := &metrics[i]
+		// Convert from MB/s to bytes/s
+		m.ReadThroughput = m.ReadThroughput * 1024 * 1024
+		m.WriteThroughput = m.WriteThroughput * 1024 * 1024
+		m.CombinedThroughput = m.CombinedThroughput * 1024 * 1024
+		// Convert IOPS weighted sum of milliseconds to average seconds
+		m.ReadResponseTime = weightedAverage(m.ReadResponseTime, m.ReadIOps) * 0.001
+		m.WriteResponseTime = weightedAverage(m.WriteResponseTime, m.WriteIOps) * 0.001
+		m.CombinedResponseTime = weightedAverage(m.CombinedResponseTime, m.CombinedIOps) * 0.001
+	}
+	return metrics, nil
+}
+
+func weightedAverage(sum float64, weight float64) float64 {
+	if weight == 0 {
+		return 0
+	}
+	return sum / weight
+}
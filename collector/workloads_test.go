@@ -0,0 +1,165 @@
+// MIT License
+//
+// Copyright (c) 2020 Ohio Supercomputer Center
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/treydock/eseries_exporter/config"
+)
+
+func TestWorkloadsCollector(t *testing.T) {
+	workloadsData, err := os.ReadFile("testdata/workloads.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	volumesData, err := os.ReadFile("testdata/volumes.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	statisticsData, err := os.ReadFile("testdata/analysed-volume-statistics.json")
+	if err != nil {
+		t.Fatalf("Error loading fixture data: %s", err.Error())
+	}
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="workloads",reason="auth"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="connection"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="decode"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="not_found"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="other"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="timeout"} 0
+	# HELP eseries_workload_capacity_bytes Capacity of volumes tagged with workload
+	# TYPE eseries_workload_capacity_bytes gauge
+	eseries_workload_capacity_bytes{workload="hpc"} 3.298534883328e+14
+	eseries_workload_capacity_bytes{workload="research"} 1.099511627776e+14
+	eseries_workload_capacity_bytes{workload="unused"} 0
+	# HELP eseries_workload_combined_response_time_seconds IOPS weighted average of volume statistic combinedResponseTime for workload
+	# TYPE eseries_workload_combined_response_time_seconds gauge
+	eseries_workload_combined_response_time_seconds{workload="hpc"} 0.004666666666666667
+	eseries_workload_combined_response_time_seconds{workload="research"} 0.001
+	eseries_workload_combined_response_time_seconds{workload="unused"} 0
+	# HELP eseries_workload_iops Sum of volume statistic combinedIOps for workload
+	# TYPE eseries_workload_iops gauge
+	eseries_workload_iops{workload="hpc"} 600
+	eseries_workload_iops{workload="research"} 200
+	eseries_workload_iops{workload="unused"} 0
+	# HELP eseries_workload_read_response_time_seconds IOPS weighted average of volume statistic readResponseTime for workload
+	# TYPE eseries_workload_read_response_time_seconds gauge
+	eseries_workload_read_response_time_seconds{workload="hpc"} 0.0035
+	eseries_workload_read_response_time_seconds{workload="research"} 0.001
+	eseries_workload_read_response_time_seconds{workload="unused"} 0
+	# HELP eseries_workload_throughput_bytes_per_second Sum of volume statistic combinedThroughput for workload
+	# TYPE eseries_workload_throughput_bytes_per_second gauge
+	eseries_workload_throughput_bytes_per_second{workload="hpc"} 6.291456e+07
+	eseries_workload_throughput_bytes_per_second{workload="research"} 2.097152e+07
+	eseries_workload_throughput_bytes_per_second{workload="unused"} 0
+	# HELP eseries_workload_volumes Number of volumes tagged with workload
+	# TYPE eseries_workload_volumes gauge
+	eseries_workload_volumes{workload="hpc"} 2
+	eseries_workload_volumes{workload="research"} 1
+	eseries_workload_volumes{workload="unused"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "workloads") {
+			_, _ = rw.Write(workloadsData)
+		} else if strings.HasSuffix(req.URL.Path, "analysed-volume-statistics") {
+			_, _ = rw.Write(statisticsData)
+		} else {
+			_, _ = rw.Write(volumesData)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewWorkloadsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 41 {
+		t.Errorf("Unexpected collection count %d, expected 41", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_workload_volumes", "eseries_workload_capacity_bytes", "eseries_workload_iops",
+		"eseries_workload_throughput_bytes_per_second", "eseries_workload_read_response_time_seconds",
+		"eseries_workload_combined_response_time_seconds", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestWorkloadsCollectorError(t *testing.T) {
+	expected := `
+	# HELP eseries_exporter_collect_error Indicates if error has occurred during collection, 1=error of reason 0=otherwise
+	# TYPE eseries_exporter_collect_error gauge
+	eseries_exporter_collect_error{collector="workloads",reason="auth"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="connection"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="decode"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="http_5xx"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="not_found"} 1
+	eseries_exporter_collect_error{collector="workloads",reason="other"} 0
+	eseries_exporter_collect_error{collector="workloads",reason="timeout"} 0
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "error", http.StatusNotFound)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL)
+	target := config.Target{
+		Name:       "test",
+		User:       "test",
+		Password:   "test",
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{},
+	}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	collector := NewWorkloadsExporter(context.Background(), target, logger)
+	gatherers := setupGatherer(collector)
+	if val, err := testutil.GatherAndCount(gatherers); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if val != 8 {
+		t.Errorf("Unexpected collection count %d, expected 8", val)
+	}
+	if err := testutil.GatherAndCompare(gatherers, strings.NewReader(expected),
+		"eseries_workload_volumes", "eseries_exporter_collect_error"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
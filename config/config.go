@@ -14,46 +14,110 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	yaml "gopkg.in/yaml.v3"
 )
 
+var (
+	configReloadSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "eseries_exporter",
+		Name:      "config_last_reload_successful",
+		Help:      "Eseries exporter config loaded successfully.",
+	})
+
+	configReloadSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "eseries_exporter",
+		Name:      "config_last_reload_success_timestamp_seconds",
+		Help:      "Timestamp of the last successful configuration reload.",
+	})
+)
+
 type Config struct {
-	Modules map[string]*Module `yaml:"modules"`
+	Modules               map[string]*Module `yaml:"modules"`
+	MaxConcurrentRequests int                `yaml:"max_concurrent_requests"`
+	limiters              map[string]*Limiter
+	global                chan struct{}
 }
 
 type SafeConfig struct {
 	sync.RWMutex
 	C *Config
+	// Collectors are the registered collector names, when set the keys of
+	// collector_poll_intervals must be one of them
+	Collectors []string
 }
 
 type Module struct {
-	User        string   `yaml:"user"`
-	Password    string   `yaml:"password"`
-	ProxyURL    string   `yaml:"proxy_url"`
-	Collectors  []string `yaml:"collectors"`
-	Timeout     int      `yaml:"timeout"`
-	InsecureSSL bool     `yaml:"insecure_ssl"`
-	RootCA      string   `yaml:"root_ca"`
+	User                   string         `yaml:"user"`
+	Password               string         `yaml:"password"`
+	ProxyURL               string         `yaml:"proxy_url"`
+	Collectors             []string       `yaml:"collectors"`
+	Timeout                int            `yaml:"timeout"`
+	InsecureSSL            bool           `yaml:"insecure_ssl"`
+	RootCA                 string         `yaml:"root_ca"`
+	MaxIdleConns           int            `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost    int            `yaml:"max_idle_conns_per_host"`
+	IdleConnTimeout        int            `yaml:"idle_conn_timeout"`
+	PollTargets            []string       `yaml:"poll_targets"`
+	PollInterval           int            `yaml:"poll_interval"`
+	PollIntervals          map[string]int `yaml:"collector_poll_intervals"`
+	PollMaxAge             int            `yaml:"poll_max_age"`
+	ScrapeCacheTTL         int            `yaml:"scrape_cache_ttl"`
+	MaxConcurrentRequests  int            `yaml:"max_concurrent_requests"`
+	Retries                int            `yaml:"retries"`
+	RetryBackoff           float64        `yaml:"retry_backoff"`
+	CircuitBreakerFailures int            `yaml:"circuit_breaker_failures"`
+	CircuitBreakerCooldown int            `yaml:"circuit_breaker_cooldown"`
+	HttpClient             *http.Client   `yaml:"-"`
+	Limiter                *Limiter       `yaml:"-"`
 }
 
 type Target struct {
-	Name       string
-	User       string
-	Password   string
-	ProxyURL   string
-	Collectors []string
-	BaseURL    *url.URL
-	HttpClient *http.Client
+	Name           string
+	Module         string
+	User           string
+	Password       string
+	ProxyURL       string
+	Collectors     []string
+	BaseURL        *url.URL
+	HttpClient     *http.Client
+	Limiter        *Limiter
+	Retries        int
+	RetryBackoff   time.Duration
+	RequestCache   RequestCache
+	CircuitBreaker CircuitBreaker
+}
+
+// CircuitBreaker records the result of each request sent to a storage system
+type CircuitBreaker interface {
+	Record(err error)
+}
+
+// RequestCache shares API responses between the collectors of a single scrape
+type RequestCache interface {
+	Get(path string, fetch func() ([]byte, error)) ([]byte, error)
 }
 
-func (sc *SafeConfig) ReloadConfig(configFile string) error {
+func (sc *SafeConfig) ReloadConfig(configFile string) (err error) {
 	var c = &Config{}
+	defer func() {
+		if err != nil {
+			configReloadSuccess.Set(0)
+		} else {
+			configReloadSuccess.Set(1)
+			configReloadSeconds.SetToCurrentTime()
+		}
+	}()
 	yamlReader, err := os.Open(configFile)
 	if err != nil {
 		return fmt.Errorf("Error reading config file %s: %s", configFile, err)
@@ -69,6 +133,24 @@ func (sc *SafeConfig) ReloadConfig(configFile string) error {
 		if module.Timeout == 0 {
 			module.Timeout = 10
 		}
+		if module.MaxIdleConns == 0 {
+			module.MaxIdleConns = 100
+		}
+		if module.MaxIdleConnsPerHost == 0 {
+			module.MaxIdleConnsPerHost = 10
+		}
+		if module.IdleConnTimeout == 0 {
+			module.IdleConnTimeout = 90
+		}
+		if module.RetryBackoff == 0 {
+			module.RetryBackoff = 0.5
+		}
+		if module.CircuitBreakerCooldown == 0 {
+			module.CircuitBreakerCooldown = 60
+		}
+		if module.PollInterval == 0 {
+			module.PollInterval = 60
+		}
 		if module.ProxyURL == "" {
 			return fmt.Errorf("Module %s must define 'proxy_url' value", key)
 		}
@@ -78,10 +160,92 @@ func (sc *SafeConfig) ReloadConfig(configFile string) error {
 		if module.Password == "" {
 			return fmt.Errorf("Module %s must define 'password' value", key)
 		}
+		if module.PollInterval < 0 {
+			return fmt.Errorf("Module %s 'poll_interval' must not be negative", key)
+		}
+		if module.PollMaxAge < 0 {
+			return fmt.Errorf("Module %s 'poll_max_age' must not be negative", key)
+		}
+		for name, interval := range module.PollIntervals {
+			if sc.Collectors != nil && !sliceContains(sc.Collectors, name) {
+				return fmt.Errorf("Module %s 'collector_poll_intervals' has unknown collector %s", key, name)
+			}
+			if interval <= 0 {
+				return fmt.Errorf("Module %s 'collector_poll_intervals' of %s must be positive", key, name)
+			}
+		}
+		httpClient, err := NewHttpClient(module)
+		if err != nil {
+			return fmt.Errorf("Module %s: %s", key, err)
+		}
+		module.HttpClient = httpClient
 		c.Modules[key] = module
 	}
 	sc.Lock()
+	old := sc.C
+	c.limiters = newLimiters(c, old)
+	for _, module := range c.Modules {
+		module.Limiter = c.limiters[module.ProxyURL]
+	}
 	sc.C = c
 	sc.Unlock()
+	// Release idle connections held by the clients of the replaced config
+	if old != nil {
+		for _, module := range old.Modules {
+			if module.HttpClient != nil {
+				module.HttpClient.CloseIdleConnections()
+			}
+		}
+	}
 	return nil
 }
+
+// NewHttpClient returns a client for communicating with the module's proxy.
+// The client is built once per config load so connections are reused between scrapes.
+func NewHttpClient(module *Module) (*http.Client, error) {
+	proxyURL, err := url.Parse(module.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse proxy_url %s: %s", module.ProxyURL, err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = module.MaxIdleConns
+	transport.MaxIdleConnsPerHost = module.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = time.Duration(module.IdleConnTimeout) * time.Second
+	if proxyURL.Scheme == "https" {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			// An empty pool is only usable with root_ca or insecure_ssl
+			if module.RootCA == "" && !module.InsecureSSL {
+				return nil, fmt.Errorf("Error loading system cert pool: %s", err)
+			}
+			rootCAs = x509.NewCertPool()
+		}
+		if module.RootCA != "" {
+			certs, err := os.ReadFile(module.RootCA)
+			if err != nil {
+				return nil, fmt.Errorf("Error loading root CA %s: %s", module.RootCA, err)
+			}
+			if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
+				return nil, fmt.Errorf("Error appending root CA %s to pool", module.RootCA)
+			}
+		}
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: module.InsecureSSL,
+			RootCAs:            rootCAs,
+		}
+	}
+	httpClient := &http.Client{
+		Timeout:   time.Duration(module.Timeout) * time.Second,
+		Transport: transport,
+	}
+	return httpClient, nil
+}
+
+func sliceContains(slice []string, str string) bool {
+	for _, s := range slice {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
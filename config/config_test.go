@@ -14,7 +14,17 @@
 package config
 
 import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestReloadConfigDefaults(t *testing.T) {
@@ -32,10 +42,19 @@ func TestReloadConfigDefaults(t *testing.T) {
 	if module.User != "monitor" {
 		t.Errorf("Module User does not match monitor")
 	}
+	if module.HttpClient == nil {
+		t.Errorf("Module HttpClient not created")
+	}
+	if module.Limiter == nil {
+		t.Errorf("Module Limiter not created")
+	}
+	if module.MaxIdleConnsPerHost != 10 {
+		t.Errorf("Module MaxIdleConnsPerHost does not match default 10")
+	}
 }
 
 func TestReloadConfigBadConfigs(t *testing.T) {
-	sc := &SafeConfig{}
+	sc := &SafeConfig{Collectors: []string{"drives"}}
 	tests := []struct {
 		ConfigFile    string
 		ExpectedError string
@@ -60,6 +79,18 @@ func TestReloadConfigBadConfigs(t *testing.T) {
 			ConfigFile:    "testdata/missing-password.yaml",
 			ExpectedError: "Module default must define 'password' value",
 		},
+		{
+			ConfigFile:    "testdata/bad-root-ca.yaml",
+			ExpectedError: "Module default: Error loading root CA /dne: open /dne: no such file or directory",
+		},
+		{
+			ConfigFile:    "testdata/negative-poll-interval.yaml",
+			ExpectedError: "Module default 'poll_interval' must not be negative",
+		},
+		{
+			ConfigFile:    "testdata/unknown-poll-collector.yaml",
+			ExpectedError: "Module default 'collector_poll_intervals' has unknown collector drive",
+		},
 	}
 	for i, test := range tests {
 		err := sc.ReloadConfig(test.ConfigFile)
@@ -72,3 +103,63 @@ func TestReloadConfigBadConfigs(t *testing.T) {
 		}
 	}
 }
+
+func TestReloadConfigKeepsConfigOnError(t *testing.T) {
+	sc := &SafeConfig{}
+	err := sc.ReloadConfig("testdata/eseries_exporter.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected err: %s", err.Error())
+	}
+	if val := testutil.ToFloat64(configReloadSuccess); val != 1 {
+		t.Errorf("Unexpected config_last_reload_successful %v, expected 1", val)
+	}
+	if val := testutil.ToFloat64(configReloadSeconds); val == 0 {
+		t.Errorf("Expected config_last_reload_success_timestamp_seconds to be set")
+	}
+	c := sc.C
+	err = sc.ReloadConfig("testdata/missing-user.yaml")
+	if err == nil {
+		t.Fatalf("Expected error loading bad config")
+	}
+	if sc.C != c {
+		t.Errorf("Config was replaced after failed reload")
+	}
+	if val := testutil.ToFloat64(configReloadSuccess); val != 0 {
+		t.Errorf("Unexpected config_last_reload_successful %v, expected 0", val)
+	}
+}
+
+func TestReloadConfigClosesIdleConnections(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	server.Start()
+	defer server.Close()
+	configFile := filepath.Join(t.TempDir(), "eseries_exporter.yaml")
+	data := fmt.Sprintf("modules:\n  default:\n    user: monitor\n    password: secret\n    proxy_url: %s\n", server.URL)
+	if err := os.WriteFile(configFile, []byte(data), 0644); err != nil {
+		t.Fatalf("Unexpected error writing config: %s", err.Error())
+	}
+	sc := &SafeConfig{}
+	if err := sc.ReloadConfig(configFile); err != nil {
+		t.Fatalf("Unexpected err: %s", err.Error())
+	}
+	resp, err := sc.C.Modules["default"].HttpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err := sc.ReloadConfig(configFile); err != nil {
+		t.Fatalf("Unexpected err: %s", err.Error())
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Idle connection of previous config was not closed")
+	}
+}
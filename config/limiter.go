@@ -0,0 +1,135 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	proxyRequestsQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "eseries_exporter",
+		Name:      "proxy_requests_queued",
+		Help:      "Number of requests waiting for a free slot to the proxy",
+	}, []string{"proxy_url"})
+	proxyRequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "eseries_exporter",
+		Name:      "proxy_requests_in_flight",
+		Help:      "Number of requests in flight to the proxy",
+	}, []string{"proxy_url"})
+	proxyRequestWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "eseries_exporter",
+		Name:      "proxy_request_wait_seconds",
+		Help:      "Time requests waited for a free slot to the proxy",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"proxy_url"})
+)
+
+// Limiter caps the number of requests in flight to a single proxy_url and
+// to all proxies when the global max_concurrent_requests is set
+type Limiter struct {
+	proxyURL string
+	limit    int
+	slots    chan struct{}
+	global   chan struct{}
+}
+
+// NewLimiter returns a limiter of limit requests to proxyURL, a limit of 0
+// is unlimited and global is the slots shared by all limiters or nil
+func NewLimiter(proxyURL string, limit int, global chan struct{}) *Limiter {
+	l := &Limiter{proxyURL: proxyURL, limit: limit, global: global}
+	if limit > 0 {
+		l.slots = make(chan struct{}, limit)
+	}
+	return l
+}
+
+// Acquire waits for a free slot, the returned function releases the slot
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	start := time.Now()
+	queued := proxyRequestsQueued.WithLabelValues(l.proxyURL)
+	queued.Inc()
+	// The proxy slot is taken first so requests queued on a busy proxy do
+	// not hold global slots needed by requests to other proxies
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			queued.Dec()
+			return nil, ctx.Err()
+		}
+	}
+	if l.global != nil {
+		select {
+		case l.global <- struct{}{}:
+		case <-ctx.Done():
+			queued.Dec()
+			if l.slots != nil {
+				<-l.slots
+			}
+			return nil, ctx.Err()
+		}
+	}
+	queued.Dec()
+	proxyRequestWait.WithLabelValues(l.proxyURL).Observe(time.Since(start).Seconds())
+	inFlight := proxyRequestsInFlight.WithLabelValues(l.proxyURL)
+	inFlight.Inc()
+	return func() {
+		inFlight.Dec()
+		if l.global != nil {
+			<-l.global
+		}
+		if l.slots != nil {
+			<-l.slots
+		}
+	}, nil
+}
+
+// newLimiters builds the limiter of each proxy_url in c using the smallest
+// max_concurrent_requests of the modules sharing it, limiters of old are
+// reused when their limits are unchanged so requests in flight stay counted
+func newLimiters(c *Config, old *Config) map[string]*Limiter {
+	limits := make(map[string]int)
+	for _, module := range c.Modules {
+		limit, ok := limits[module.ProxyURL]
+		if !ok || (module.MaxConcurrentRequests > 0 && (limit == 0 || module.MaxConcurrentRequests < limit)) {
+			limits[module.ProxyURL] = module.MaxConcurrentRequests
+		}
+	}
+	var global chan struct{}
+	if old != nil && old.MaxConcurrentRequests == c.MaxConcurrentRequests {
+		global = old.global
+	} else if c.MaxConcurrentRequests > 0 {
+		global = make(chan struct{}, c.MaxConcurrentRequests)
+	}
+	c.global = global
+	limiters := make(map[string]*Limiter)
+	for proxyURL, limit := range limits {
+		if old != nil {
+			if l, ok := old.limiters[proxyURL]; ok && l.limit == limit && l.global == global {
+				limiters[proxyURL] = l
+				continue
+			}
+		}
+		limiters[proxyURL] = NewLimiter(proxyURL, limit, global)
+	}
+	return limiters
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 Trey Dockendorf
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter("http://limited", 1, nil)
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if val := testutil.ToFloat64(proxyRequestsInFlight.WithLabelValues("http://limited")); val != 1 {
+		t.Errorf("Unexpected in flight requests %v, expected 1", val)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded waiting for slot, got %v", err)
+	}
+	release()
+	if val := testutil.ToFloat64(proxyRequestsQueued.WithLabelValues("http://limited")); val != 0 {
+		t.Errorf("Unexpected queued requests %v, expected 0", val)
+	}
+	if val := testutil.ToFloat64(proxyRequestsInFlight.WithLabelValues("http://limited")); val != 0 {
+		t.Errorf("Unexpected in flight requests %v, expected 0", val)
+	}
+}
+
+func TestLimiterGlobal(t *testing.T) {
+	global := make(chan struct{}, 1)
+	first := NewLimiter("http://first", 0, global)
+	second := NewLimiter("http://second", 0, global)
+	release, err := first.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := second.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded waiting for global slot, got %v", err)
+	}
+	release()
+	release, err = second.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	release()
+}
+
+func TestNewLimiters(t *testing.T) {
+	c := &Config{Modules: map[string]*Module{
+		"a": {ProxyURL: "http://proxy1", MaxConcurrentRequests: 0},
+		"b": {ProxyURL: "http://proxy1", MaxConcurrentRequests: 8},
+		"c": {ProxyURL: "http://proxy1", MaxConcurrentRequests: 4},
+		"d": {ProxyURL: "http://proxy2"},
+	}}
+	c.limiters = newLimiters(c, nil)
+	if limit := c.limiters["http://proxy1"].limit; limit != 4 {
+		t.Errorf("Unexpected limit %d for shared proxy, expected 4", limit)
+	}
+	if limit := c.limiters["http://proxy2"].limit; limit != 0 {
+		t.Errorf("Unexpected limit %d for unlimited proxy, expected 0", limit)
+	}
+	if c.global != nil {
+		t.Errorf("Unexpected global limit without max_concurrent_requests")
+	}
+
+	reloaded := &Config{Modules: map[string]*Module{
+		"a": {ProxyURL: "http://proxy1", MaxConcurrentRequests: 4},
+		"d": {ProxyURL: "http://proxy2", MaxConcurrentRequests: 2},
+	}}
+	reloaded.limiters = newLimiters(reloaded, c)
+	if reloaded.limiters["http://proxy1"] != c.limiters["http://proxy1"] {
+		t.Errorf("Expected limiter to be reused when limit is unchanged")
+	}
+	if reloaded.limiters["http://proxy2"] == c.limiters["http://proxy2"] {
+		t.Errorf("Expected new limiter when limit changes")
+	}
+
+	global := &Config{Modules: reloaded.Modules, MaxConcurrentRequests: 10}
+	global.limiters = newLimiters(global, reloaded)
+	if cap(global.global) != 10 {
+		t.Errorf("Unexpected global limit %d, expected 10", cap(global.global))
+	}
+	for proxyURL, l := range global.limiters {
+		if l.global != global.global {
+			t.Errorf("Expected limiter of %s to use the global limit", proxyURL)
+		}
+	}
+}
+
+func TestLimiterGlobalNotHeldByQueuedProxy(t *testing.T) {
+	global := make(chan struct{}, 3)
+	busy := NewLimiter("http://busy", 2, global)
+	idle := NewLimiter("http://idle", 0, global)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, err := busy.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		releases = append(releases, release)
+	}
+	queued := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			queued <- struct{}{}
+			if release, err := busy.Acquire(ctx); err == nil {
+				release()
+			}
+		}()
+		<-queued
+	}
+	time.Sleep(10 * time.Millisecond)
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+	defer waitCancel()
+	release, err := idle.Acquire(waitCtx)
+	if err != nil {
+		t.Fatalf("Expected idle proxy to not be blocked by queue of busy proxy, got %v", err)
+	}
+	release()
+	for _, release := range releases {
+		release()
+	}
+}
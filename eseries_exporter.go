@@ -14,12 +14,14 @@
 package main
 
 import (
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/go-kit/log"
@@ -37,32 +39,35 @@ import (
 var (
 	configFile    = kingpin.Flag("config.file", "Path to exporter config file").Default("eseries_exporter.yaml").String()
 	listenAddress = kingpin.Flag("web.listen-address", "Address to listen on for web interface and telemetry.").Default(":9313").String()
+	timeoutOffset = kingpin.Flag("web.timeout-offset", "Seconds to subtract from the Prometheus scrape timeout when collecting.").Default("0.5").Float64()
 )
 
-func metricsHandler(c *config.Config, logger log.Logger) http.HandlerFunc {
+func metricsHandler(sc *config.SafeConfig, poller *collector.Poller, group *collector.ScrapeGroup, breakers *collector.CircuitBreakers, logger log.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		registry := prometheus.NewRegistry()
 
 		t := r.URL.Query().Get("target")
-		if t == "" {
-			http.Error(w, "'target' parameter must be specified", http.StatusBadRequest)
-			return
-		}
 		m := r.URL.Query().Get("module")
 		if m == "" {
 			m = "default"
 		}
-		module, ok := c.Modules[m]
+		sc.RLock()
+		module, ok := sc.C.Modules[m]
+		sc.RUnlock()
 		if !ok {
-			http.Error(w, fmt.Sprintf("Unknown module %s", t), http.StatusNotFound)
+			http.Error(w, fmt.Sprintf("Unknown module %s", m), http.StatusNotFound)
 			return
 		}
 		target := config.Target{
-			Name:       t,
-			User:       module.User,
-			Password:   module.Password,
-			ProxyURL:   module.ProxyURL,
-			Collectors: module.Collectors,
+			Name:         t,
+			Module:       m,
+			User:         module.User,
+			Password:     module.Password,
+			ProxyURL:     module.ProxyURL,
+			Collectors:   module.Collectors,
+			Limiter:      module.Limiter,
+			Retries:      module.Retries,
+			RetryBackoff: time.Duration(module.RetryBackoff * float64(time.Second)),
 		}
 		proxyURL, err := url.Parse(module.ProxyURL)
 		if err != nil {
@@ -71,40 +76,43 @@ func metricsHandler(c *config.Config, logger log.Logger) http.HandlerFunc {
 		} else {
 			target.BaseURL = proxyURL
 		}
-		httpClient := &http.Client{
-			Timeout: time.Duration(module.Timeout) * time.Second,
+		target.HttpClient = module.HttpClient
+		ctx, cancel := scrapeContext(r, logger)
+		defer cancel()
+		if t != "" && poller.Polled(m, t) {
+			level.Debug(logger).Log("msg", "Serving background collection", "module", m, "target", t)
+			registry.MustRegister(poller.Collector(m, t))
+			h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+			h.ServeHTTP(w, r)
+			return
 		}
-		if proxyURL.Scheme == "https" {
-			level.Debug(logger).Log("msg", "Setting up SSL transport", "url", module.ProxyURL)
-			rootCAs, err := x509.SystemCertPool()
-			if err != nil {
-				level.Error(logger).Log("msg", "Error loading system cert pool, creating empty cert pool", "err", err)
-				rootCAs = x509.NewCertPool()
+		if t != "" && module.CircuitBreakerFailures > 0 {
+			cooldown := time.Duration(module.CircuitBreakerCooldown) * time.Second
+			breaker := breakers.Get(m+"/"+t, module.CircuitBreakerFailures, cooldown)
+			registry.MustRegister(breaker.Collector(t))
+			if !breaker.Allow(ctx, target, logger) {
+				level.Debug(logger).Log("msg", "Skipping collection of target with open circuit", "module", m, "target", t)
+				h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+				h.ServeHTTP(w, r)
+				return
 			}
-			if module.RootCA != "" {
-				certs, err := os.ReadFile(module.RootCA)
-				if err != nil {
-					level.Error(logger).Log("msg", "Error loading root CA", "rootCA", module.RootCA, "err", err)
-					http.Error(w, "Error loading root CA", http.StatusBadRequest)
-					return
-				}
-				if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
-					level.Error(logger).Log("msg", "Error appending root CA to pool", "rootCA", module.RootCA, "err", err)
-				}
-			}
-			tlsConfig := &tls.Config{
-				InsecureSkipVerify: module.InsecureSSL,
-				RootCAs:            rootCAs,
-			}
-			tlsTransport := &http.Transport{TLSClientConfig: tlsConfig}
-			httpClient.Transport = tlsTransport
+			scrape := breaker.Scrape()
+			target.CircuitBreaker = scrape
+			defer scrape.Finish()
+		}
+		var eseriesCollector *collector.EseriesCollector
+		if t == "" {
+			level.Debug(logger).Log("msg", "No target specified, collecting from proxy", "module", m)
+			eseriesCollector = collector.NewProxyCollector(ctx, target, logger)
+		} else {
+			eseriesCollector = collector.NewCollector(ctx, target, logger)
 		}
-		target.HttpClient = httpClient
-		eseriesCollector := collector.NewCollector(target, logger)
-		for key, collector := range eseriesCollector.Collectors {
+		for key := range eseriesCollector.Collectors {
 			level.Debug(logger).Log("msg", fmt.Sprintf("Enabled collector %s", key))
-			registry.MustRegister(collector)
 		}
+		key := collector.ScrapeKey(m, t, eseriesCollector.Collectors)
+		ttl := time.Duration(module.ScrapeCacheTTL) * time.Second
+		registry.MustRegister(group.Collector(ctx, key, ttl, eseriesCollector.Collectors))
 
 		gatherers := prometheus.Gatherers{registry}
 
@@ -114,6 +122,39 @@ func metricsHandler(c *config.Config, logger log.Logger) http.HandlerFunc {
 	}
 }
 
+// scrapeContext returns the request context with a deadline derived from the
+// Prometheus scrape timeout less the configured offset
+func scrapeContext(r *http.Request, logger log.Logger) (context.Context, context.CancelFunc) {
+	header := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if header == "" {
+		return context.WithCancel(r.Context())
+	}
+	timeoutSeconds, err := strconv.ParseFloat(header, 64)
+	if err != nil {
+		level.Error(logger).Log("msg", "Unable to parse scrape timeout", "value", header, "err", err)
+		return context.WithCancel(r.Context())
+	}
+	if timeoutSeconds > *timeoutOffset {
+		timeoutSeconds -= *timeoutOffset
+	}
+	return context.WithTimeout(r.Context(), time.Duration(timeoutSeconds*float64(time.Second)))
+}
+
+func reloadHandler(reloadCh chan chan error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			fmt.Fprintf(w, "This endpoint requires a POST request.\n")
+			return
+		}
+		rc := make(chan error)
+		reloadCh <- rc
+		if err := <-rc; err != nil {
+			http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
+		}
+	}
+}
+
 func main() {
 	metricsEndpoint := "/eseries"
 	promlogConfig := &promlog.Config{}
@@ -127,13 +168,42 @@ func main() {
 	level.Info(logger).Log("msg", "Build context", "build_context", version.BuildContext())
 	level.Info(logger).Log("msg", "Starting Server", "address", *listenAddress)
 
-	sc := &config.SafeConfig{}
+	sc := &config.SafeConfig{Collectors: collector.Names()}
 
 	if err := sc.ReloadConfig(*configFile); err != nil {
 		level.Error(logger).Log("msg", "Error loading config", "err", err)
 		os.Exit(1)
 	}
 
+	poller := collector.NewPoller(logger)
+	reload := func() error {
+		if err := sc.ReloadConfig(*configFile); err != nil {
+			level.Error(logger).Log("msg", "Error reloading config", "err", err)
+			return err
+		}
+		level.Info(logger).Log("msg", "Loaded config file")
+		sc.RLock()
+		c := sc.C
+		sc.RUnlock()
+		poller.Update(c)
+		return nil
+	}
+	poller.Update(sc.C)
+
+	hup := make(chan os.Signal, 1)
+	reloadCh := make(chan chan error)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				_ = reload()
+			case rc := <-reloadCh:
+				rc <- reload()
+			}
+		}
+	}()
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		//nolint:errcheck
 		w.Write([]byte(`<html>
@@ -145,7 +215,8 @@ func main() {
              </body>
              </html>`))
 	})
-	http.Handle(metricsEndpoint, metricsHandler(sc.C, logger))
+	http.Handle(metricsEndpoint, metricsHandler(sc, poller, collector.NewScrapeGroup(), collector.NewCircuitBreakers(), logger))
+	http.Handle("/-/reload", reloadHandler(reloadCh))
 	http.Handle("/metrics", promhttp.Handler())
 	err := http.ListenAndServe(*listenAddress, nil)
 	if err != nil {
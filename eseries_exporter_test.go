@@ -19,29 +19,37 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
+	"github.com/treydock/eseries_exporter/collector"
 	"github.com/treydock/eseries_exporter/config"
 )
 
-const (
-	address = "localhost:19313"
-)
-
-func SetupServer() *config.Config {
+func SetupServer() *config.SafeConfig {
 	fixtureData, err := os.ReadFile("collector/testdata/drives.json")
 	if err != nil {
 		fmt.Printf("Error loading fixture data: %s", err.Error())
 		os.Exit(1)
 	}
-	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		_, _ = rw.Write(fixtureData)
-	}))
-	sslServer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		_, _ = rw.Write(fixtureData)
-	}))
+	proxyFixtureData, err := os.ReadFile("collector/testdata/proxy-storage-systems.json")
+	if err != nil {
+		fmt.Printf("Error loading fixture data: %s", err.Error())
+		os.Exit(1)
+	}
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/storage-systems") {
+			_, _ = rw.Write(proxyFixtureData)
+		} else {
+			_, _ = rw.Write(fixtureData)
+		}
+	})
+	server := httptest.NewServer(handler)
+	sslServer := httptest.NewTLSServer(handler)
 	module := &config.Module{
 		User:       "test",
 		Password:   "test",
@@ -56,58 +64,240 @@ func SetupServer() *config.Config {
 		RootCA:      "collector/testdata/rootCA.crt",
 		InsecureSSL: true,
 	}
-	sslBadModule := &config.Module{
-		User:        "test",
-		Password:    "test",
-		Collectors:  []string{"drives"},
-		ProxyURL:    sslServer.URL,
-		RootCA:      "/dne",
-		InsecureSSL: true,
+	for _, m := range []*config.Module{module, sslModule} {
+		httpClient, err := config.NewHttpClient(m)
+		if err != nil {
+			fmt.Printf("Error creating HTTP client: %s", err.Error())
+			os.Exit(1)
+		}
+		m.HttpClient = httpClient
 	}
 	c := &config.Config{}
 	c.Modules = make(map[string]*config.Module)
 	c.Modules["default"] = module
 	c.Modules["ssl"] = sslModule
-	c.Modules["ssl-error"] = sslBadModule
-	return c
+	return &config.SafeConfig{C: c}
 }
 
 func TestMetricsHandler(t *testing.T) {
-	c := SetupServer()
+	sc := SetupServer()
 	w := log.NewSyncWriter(os.Stderr)
 	logger := log.NewLogfmtLogger(w)
-	go func() {
-		http.Handle("/eseries", metricsHandler(c, logger))
-		err := http.ListenAndServe(address, nil)
-		if err != nil {
-			os.Exit(1)
+	mux := http.NewServeMux()
+	mux.Handle("/eseries", metricsHandler(sc, collector.NewPoller(logger), collector.NewScrapeGroup(), collector.NewCircuitBreakers(), logger))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	body, err := queryExporter(server.URL, "target=test1", http.StatusOK)
+	if err != nil {
+		t.Fatalf("Unexpected error GET /eseries: %s", err.Error())
+	}
+	if reason := collectErrorReason(body, "drives"); reason != "" {
+		t.Errorf("Unexpected eseries_exporter_collect_error reason %s", reason)
+	}
+	if !strings.Contains(body, "eseries_exporter_collect_timeout{collector=\"drives\"} 0") {
+		t.Errorf("Unexpected value for eseries_exporter_collect_timeout")
+	}
+
+	body, err = queryExporter(server.URL, "target=test1&module=ssl", http.StatusOK)
+	if err != nil {
+		t.Fatalf("Unexpected error GET /eseries: %s", err.Error())
+	}
+	if reason := collectErrorReason(body, "drives"); reason != "" {
+		t.Errorf("Unexpected eseries_exporter_collect_error reason %s", reason)
+	}
+
+	body, err = queryExporter(server.URL, "", http.StatusOK)
+	if err != nil {
+		t.Fatalf("Unexpected error GET /eseries: %s", err.Error())
+	}
+	if reason := collectErrorReason(body, "proxy-storage-systems"); reason != "" {
+		t.Errorf("Unexpected eseries_exporter_collect_error reason %s", reason)
+	}
+	if strings.Contains(body, "eseries_exporter_collect_error{collector=\"drives\"") {
+		t.Errorf("Unexpected drives collector run without target")
+	}
+
+	if _, err := queryExporter(server.URL, "module=dne", http.StatusNotFound); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+
+	sc.Lock()
+	sc.C = &config.Config{Modules: map[string]*config.Module{"dne": sc.C.Modules["default"]}}
+	sc.Unlock()
+	if _, err := queryExporter(server.URL, "module=dne&target=test1", http.StatusOK); err != nil {
+		t.Errorf("Unexpected error after config change: %s", err.Error())
+	}
+	if _, err := queryExporter(server.URL, "target=test1", http.StatusNotFound); err != nil {
+		t.Errorf("Unexpected error after config change: %s", err.Error())
+	}
+}
+
+func TestMetricsHandlerScrapeTimeout(t *testing.T) {
+	done := make(chan struct{})
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-done:
 		}
-	}()
-	body, err := queryExporter("target=test1", http.StatusOK)
+	}))
+	defer proxy.Close()
+	defer close(done)
+	module := &config.Module{
+		User:       "test",
+		Password:   "test",
+		Collectors: []string{"drives"},
+		ProxyURL:   proxy.URL,
+		Timeout:    10,
+	}
+	httpClient, err := config.NewHttpClient(module)
+	if err != nil {
+		t.Fatalf("Unexpected error creating HTTP client: %s", err.Error())
+	}
+	module.HttpClient = httpClient
+	sc := &config.SafeConfig{C: &config.Config{Modules: map[string]*config.Module{"default": module}}}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	mux := http.NewServeMux()
+	mux.Handle("/eseries", metricsHandler(sc, collector.NewPoller(logger), collector.NewScrapeGroup(), collector.NewCircuitBreakers(), logger))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/eseries?target=test1", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", "0.2")
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		t.Fatalf("Unexpected error GET /eseries: %s", err.Error())
 	}
-	if !strings.Contains(body, "eseries_exporter_collect_error{collector=\"drives\"} 0") {
-		t.Errorf("Unexpected value for eseries_exporter_collect_error")
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Unexpected error reading body: %s", err.Error())
 	}
+	body := string(b)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Scrape did not honor timeout, took %s", elapsed)
+	}
+	if !strings.Contains(body, "eseries_exporter_collect_timeout{collector=\"drives\"} 1") {
+		t.Errorf("Unexpected value for eseries_exporter_collect_timeout")
+	}
+	if reason := collectErrorReason(body, "drives"); reason != "timeout" {
+		t.Errorf("Unexpected eseries_exporter_collect_error reason %s, expected timeout", reason)
+	}
+}
 
-	body, err = queryExporter("target=test1&module=ssl", http.StatusOK)
+func TestMetricsHandlerCircuitBreaker(t *testing.T) {
+	var requests int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		conn, _, _ := rw.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer proxy.Close()
+	module := &config.Module{
+		User:                   "test",
+		Password:               "test",
+		Collectors:             []string{"drives"},
+		ProxyURL:               proxy.URL,
+		Timeout:                10,
+		CircuitBreakerFailures: 2,
+		CircuitBreakerCooldown: 3600,
+	}
+	httpClient, err := config.NewHttpClient(module)
 	if err != nil {
+		t.Fatalf("Unexpected error creating HTTP client: %s", err.Error())
+	}
+	module.HttpClient = httpClient
+	sc := &config.SafeConfig{C: &config.Config{Modules: map[string]*config.Module{"default": module}}}
+	w := log.NewSyncWriter(os.Stderr)
+	logger := log.NewLogfmtLogger(w)
+	mux := http.NewServeMux()
+	mux.Handle("/eseries", metricsHandler(sc, collector.NewPoller(logger), collector.NewScrapeGroup(), collector.NewCircuitBreakers(), logger))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var body string
+	for i := 0; i < 5; i++ {
+		body, err = queryExporter(server.URL, "target=t1", http.StatusOK)
+		if err != nil {
+			t.Fatalf("Unexpected error GET /eseries: %s", err.Error())
+		}
+	}
+	if !strings.Contains(body, "eseries_exporter_target_circuit_open{target=\"t1\"} 1") {
+		t.Errorf("Expected open circuit for unreachable target after 5 scrapes")
+	}
+	if strings.Contains(body, "eseries_exporter_collect_error") {
+		t.Errorf("Unexpected collection of target with open circuit")
+	}
+	sent := atomic.LoadInt32(&requests)
+	if _, err := queryExporter(server.URL, "target=t1", http.StatusOK); err != nil {
 		t.Fatalf("Unexpected error GET /eseries: %s", err.Error())
 	}
-	if !strings.Contains(body, "eseries_exporter_collect_error{collector=\"drives\"} 0") {
-		t.Errorf("Unexpected value for eseries_exporter_collect_error")
+	if val := atomic.LoadInt32(&requests); val != sent {
+		t.Errorf("Unexpected requests to target with open circuit, got %d", val-sent)
 	}
+}
 
-	_, _ = queryExporter("target=test1&module=ssl-error", http.StatusBadRequest)
+func TestReloadHandler(t *testing.T) {
+	reloadCh := make(chan chan error)
+	reloadErr := make(chan error, 1)
+	go func() {
+		for rc := range reloadCh {
+			rc <- <-reloadErr
+		}
+	}()
+	server := httptest.NewServer(reloadHandler(reloadCh))
+	defer server.Close()
 
-	_, _ = queryExporter("", http.StatusBadRequest)
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error GET /-/reload: %s", err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Unexpected status code for GET, got %d", resp.StatusCode)
+	}
 
-	_, _ = queryExporter("module=dne", http.StatusNotFound)
+	reloadErr <- nil
+	resp, err = http.Post(server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error POST /-/reload: %s", err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected status code for successful reload, got %d", resp.StatusCode)
+	}
+
+	reloadErr <- fmt.Errorf("bad config")
+	resp, err = http.Post(server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error POST /-/reload: %s", err.Error())
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Unexpected status code for failed reload, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "bad config") {
+		t.Errorf("Unexpected body for failed reload: %s", body)
+	}
+}
+
+// collectErrorReason returns the reason of the collector's collect_error
+// metric that is set or an empty string if there is no error
+func collectErrorReason(body string, collector string) string {
+	re := regexp.MustCompile(fmt.Sprintf(`eseries_exporter_collect_error\{collector="%s",reason="([^"]+)"\} 1`, regexp.QuoteMeta(collector)))
+	if m := re.FindStringSubmatch(body); m != nil {
+		return m[1]
+	}
+	return ""
 }
 
-func queryExporter(param string, want int) (string, error) {
-	resp, err := http.Get(fmt.Sprintf("http://%s/eseries?%s", address, param))
+func queryExporter(url string, param string, want int) (string, error) {
+	resp, err := http.Get(fmt.Sprintf("%s/eseries?%s", url, param))
 	if err != nil {
 		return "", err
 	}